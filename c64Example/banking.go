@@ -0,0 +1,90 @@
+package main
+
+import (
+	"github.com/edison-moreland/go6502/c64Example/vic2"
+	"github.com/edison-moreland/go6502/memory"
+	"io/ioutil"
+	"log"
+)
+
+// Lines on the 6510 I/O port that pick what's banked in
+const (
+	LORAM  = 0x01
+	HIRAM  = 0x02
+	CHAREN = 0x04
+)
+
+// c64Banks switches BASIC, KERNAL and I/O in and out as the I/O port changes
+type c64Banks struct {
+	devices *memory.MappedBus
+	vic     *vic2.Addon
+
+	basic, kernal       *memory.Window
+	basicROM, kernalROM *memory.Bank
+	ioMapped            bool
+}
+
+func newC64Banks(ram memory.Bus, vic *vic2.Addon, basicRomPath, kernalRomPath string) (banks *c64Banks, err error) {
+	// Devices sit in front of the banks, which sit in front of RAM
+	banked := memory.NewBankedBus(ram)
+	banks = &c64Banks{devices: memory.NewMappedBus(banked), vic: vic}
+
+	if banks.basic, err = banked.AddWindow(0xA000, 0xBFFF); err != nil {
+		return nil, err
+	}
+	if banks.kernal, err = banked.AddWindow(0xE000, 0xFFFF); err != nil {
+		return nil, err
+	}
+
+	if banks.basicROM, err = loadROM("BASIC", basicRomPath); err != nil {
+		return nil, err
+	}
+	if banks.kernalROM, err = loadROM("KERNAL", kernalRomPath); err != nil {
+		return nil, err
+	}
+
+	return banks, nil
+}
+
+func loadROM(name, path string) (*memory.Bank, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return memory.NewROM(name, data), nil
+}
+
+func (cb *c64Banks) update(lines byte) {
+	// Character ROM isn't loaded, so RAM shows through when CHAREN is low
+	basic, kernal := cb.basicROM, cb.kernalROM
+	if lines&(LORAM|HIRAM) != LORAM|HIRAM {
+		basic = nil
+	}
+	if lines&HIRAM == 0 {
+		kernal = nil
+	}
+
+	if err := cb.basic.Select(basic); err != nil {
+		log.Panic(err)
+	}
+	if err := cb.kernal.Select(kernal); err != nil {
+		log.Panic(err)
+	}
+
+	io := lines&(LORAM|HIRAM) != 0 && lines&CHAREN != 0
+	if io == cb.ioMapped {
+		return
+	}
+
+	var err error
+	if io {
+		err = cb.vic.Map(cb.devices)
+	} else {
+		err = cb.devices.Unmap(vic2.IOStart)
+	}
+	if err != nil {
+		log.Panic(err)
+	}
+	cb.ioMapped = io
+}
@@ -1,12 +1,15 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"github.com/edison-moreland/go6502/c64Example/vic2"
 	"github.com/edison-moreland/go6502/cpu"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
 	"path"
 	"runtime"
 	"time"
@@ -15,21 +18,7 @@ import (
 import _ "net/http/pprof"
 
 var cpuprofile = flag.Bool("cpuprofile", false, "start profile server on localhost:6060")
-
-type StopExecutionAddon struct {
-	cpu.BaseAddon
-	cycles int
-}
-
-func (se *StopExecutionAddon) AfterExecution() {
-	if se.cycles >= 1000 {
-		se.G6.StopEmulation()
-		return
-	}
-
-	se.cycles++
-	return
-}
+var instructions = flag.Uint64("instructions", 0, "stop after this many instructions, 0 runs forever")
 
 // Relative path to C64 ROM
 const BASICRomPath = "./basic.901226-01.bin"
@@ -43,36 +32,66 @@ func main() {
 		}()
 	}
 
-	g6502 := cpu.Go6502{}
+	g6502 := cpu.New(cpu.WithVariant(cpu.MOS6510), cpu.WithRAMPattern(cpu.RAMAlternating))
+
+	// LORAM, HIRAM, CHAREN, and cassette sense are pulled up on the C64 board
+	g6502.Port().PullUps = 0x17
+
+	vic := &vic2.Addon{}
 
 	// Register Addons
 	g6502.RegisterAddons(
 		&cpu.DebugAddon{SlowDown: 25 * time.Millisecond, Step: false, ShowZP: false},
-		&vic2.Addon{},
-		//&StopExecutionAddon{},
+		vic,
 	)
 
 	// Find location of this go file
-	if _, filename, _, ok := runtime.Caller(0); ok {
-		// rom paths are relative this file
-		basicRomPath := path.Join(path.Dir(filename), BASICRomPath)
-		kernalRomPath := path.Join(path.Dir(filename), KernalRomPath)
-
-		// Load basic into memory
-		if err := g6502.Mem.LoadMem(basicRomPath, 0xA000, 0xBFFF); err != nil {
-			log.Panic(err)
-		}
+	_, filename, _, ok := runtime.Caller(0)
+	if !ok {
+		log.Panic("Could not find ROM path")
+	}
 
-		// Load kernal into memory
-		if err := g6502.Mem.LoadMem(kernalRomPath, 0xE000, 0xFFFF); err != nil {
-			log.Panic(err)
+	// rom paths are relative this file
+	basicRomPath := path.Join(path.Dir(filename), BASICRomPath)
+	kernalRomPath := path.Join(path.Dir(filename), KernalRomPath)
+
+	banks, err := newC64Banks(&g6502.Mem, vic, basicRomPath, kernalRomPath)
+	if err != nil {
+		log.Panic(err)
+	}
+	g6502.Bus = banks.devices
+
+	// The port decides what's banked in
+	port := g6502.Port()
+	port.OnChange = banks.update
+	banks.update(port.Lines(g6502.Cycles))
+
+	if err := g6502.Reset(); err != nil {
+		log.Panic(err)
+	}
+
+	// Run until interrupted
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+	go func() {
+		select {
+		case <-interrupt:
+			cancel()
+		case <-ctx.Done():
 		}
+	}()
+
+	if *instructions > 0 {
+		err = g6502.RunFor(*instructions, cpu.UnitInstructions)
 	} else {
-		log.Panic("Could not find ROM path")
+		err = g6502.Run(ctx)
 	}
 
-	err := g6502.StartEmulation()
-	if err != nil {
+	if err != nil && err != context.Canceled {
 		fmt.Printf("%+v", err)
 		panic(err)
 	}
@@ -1,23 +1,181 @@
 package vic2
 
 import (
+	"encoding/binary"
 	"fmt"
 	"github.com/edison-moreland/go6502/cpu"
+	"github.com/edison-moreland/go6502/memory"
+	"io"
 )
 
 // Vic-2 Register locations
 const ControlRegister1 = 0xD011
 const ControlRegister2 = 0xD016
 const RasterCounter = 0xD012
+const InterruptStatus = 0xD019
+const InterruptEnable = 0xD01A
+
+// The registers are mirrored every 64 bytes from $D000 to $D3FF
+const IOStart = 0xD000
+const IOEnd = 0xD3FF
+const registerCount = 0x40
+
+// PAL timing
+const CyclesPerLine = 63
+const LinesPerFrame = 312
+
+// IRQSource is the IRQ line the Vic-2 pulls on
+const IRQSource = 1
+
+// Raster interrupt bit in the interrupt registers
+const rasterInterrupt = 0x01
 
 // Vic2Addon emulates the behevior of the Video interface chip used in the Commodore ^$
 type Addon struct {
 	cpu.BaseAddon
+
+	// Registers that aren't emulated just hold what was written to them
+	registers [registerCount]byte
+
+	control1, control2 byte
+
+	// Reading $D012 gives the current line, writing it sets the line to interrupt on
+	rasterLine, rasterCompare uint16
+
+	interruptStatus, interruptEnable byte
+
+	// Fires at the start of every raster line
+	line *cpu.Event
+}
+
+// Everything that goes in a save state
+type state struct {
+	Registers                        [registerCount]byte
+	Control1, Control2               byte
+	RasterLine, RasterCompare        uint16
+	InterruptStatus, InterruptEnable byte
+	NextLine                         uint64
+}
+
+func (v2 *Addon) Register(g6 *cpu.Go6502) {
+	v2.BaseAddon.Register(g6)
+	v2.line = g6.ScheduleIn(CyclesPerLine, v2.nextLine)
+}
+
+func (v2 *Addon) nextLine(cycle uint64) {
+	v2.rasterLine = (v2.rasterLine + 1) % LinesPerFrame
+
+	if v2.rasterLine == v2.rasterCompare {
+		v2.interruptStatus |= rasterInterrupt
+		v2.updateIRQ()
+	}
+
+	v2.G6.Reschedule(v2.line, cycle+CyclesPerLine)
+}
+
+func (v2 *Addon) updateIRQ() {
+	if v2.irqActive() {
+		v2.G6.AssertIRQ(IRQSource)
+	} else {
+		v2.G6.ReleaseIRQ(IRQSource)
+	}
 }
 
-func (v2 *Addon) AfterExecution() {
-	control1, _ := v2.G6.Mem.ReadByte(ControlRegister1)
-	control2, _ := v2.G6.Mem.ReadByte(ControlRegister2)
-	rasterCounter, _ := v2.G6.Mem.ReadByte(RasterCounter)
-	fmt.Printf("Vic20 - CR1: (%#v)  CR2: (%#v)  RC: (%v) ", control1, control2, rasterCounter)
+func (v2 *Addon) irqActive() bool {
+	return v2.interruptStatus&v2.interruptEnable&0x0F != 0
+}
+
+func (v2 *Addon) Map(bus *memory.MappedBus) error {
+	// Claims the Vic-2's I/O area on bus
+	return bus.Map(IOStart, IOEnd, memory.Device{
+		Read:  v2.read,
+		Write: v2.write,
+	})
+}
+
+func (v2 *Addon) read(address uint16) byte {
+	// Reading the registers has no side effects, so read doubles as peek
+	switch register(address) {
+	case ControlRegister1:
+		// Bit 8 of the raster line lives in the top bit of control register 1
+		return v2.control1&0x7F | byte(v2.rasterLine>>8)<<7
+	case RasterCounter:
+		return byte(v2.rasterLine)
+	case InterruptStatus:
+		// Bit 7 is set while any enabled interrupt is pending, unused bits read as 1
+		status := v2.interruptStatus | 0x70
+		if v2.irqActive() {
+			status |= 0x80
+		}
+		return status
+	case InterruptEnable:
+		return v2.interruptEnable | 0xF0
+	}
+
+	return v2.registers[address%registerCount]
+}
+
+func (v2 *Addon) write(address uint16, value byte) {
+	v2.registers[address%registerCount] = value
+
+	switch register(address) {
+	case ControlRegister1:
+		v2.control1 = value
+		v2.rasterCompare = v2.rasterCompare&0xFF | uint16(value>>7)<<8
+	case ControlRegister2:
+		v2.control2 = value
+	case RasterCounter:
+		v2.rasterCompare = v2.rasterCompare&0x100 | uint16(value)
+	case InterruptStatus:
+		// Writing a 1 acknowledges that interrupt
+		v2.interruptStatus &^= value & 0x0F
+		v2.updateIRQ()
+		return
+	case InterruptEnable:
+		v2.interruptEnable = value & 0x0F
+		v2.updateIRQ()
+		return
+	default:
+		return
+	}
+
+	fmt.Printf("Vic20 - CR1: (%#v)  CR2: (%#v)  RC: (%v) ", v2.control1, v2.control2, v2.rasterCompare)
+}
+
+func register(address uint16) uint16 {
+	// Folds a mirrored address down to the register at $D000-$D03F
+	return IOStart + address%registerCount
+}
+
+func (v2 *Addon) StateName() string {
+	return "vic2"
+}
+
+func (v2 *Addon) SaveState(w io.Writer) error {
+	return binary.Write(w, binary.LittleEndian, state{
+		Registers:       v2.registers,
+		Control1:        v2.control1,
+		Control2:        v2.control2,
+		RasterLine:      v2.rasterLine,
+		RasterCompare:   v2.rasterCompare,
+		InterruptStatus: v2.interruptStatus,
+		InterruptEnable: v2.interruptEnable,
+		NextLine:        v2.line.Cycle(),
+	})
+}
+
+func (v2 *Addon) LoadState(r io.Reader) error {
+	var s state
+	if err := binary.Read(r, binary.LittleEndian, &s); err != nil {
+		return err
+	}
+
+	v2.registers = s.Registers
+	v2.control1, v2.control2 = s.Control1, s.Control2
+	v2.rasterLine, v2.rasterCompare = s.RasterLine, s.RasterCompare
+	v2.interruptStatus, v2.interruptEnable = s.InterruptStatus, s.InterruptEnable
+
+	v2.G6.Reschedule(v2.line, s.NextLine)
+	v2.updateIRQ()
+	return nil
 }
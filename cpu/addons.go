@@ -10,6 +10,66 @@ type Addon interface {
 	AfterExecution()
 }
 
+/*
+Optional hooks
+--------------
+Addons can implement any of these on top of Addon. The CPU checks which ones
+an addon has when it's registered, and only calls those, hooks nobody
+implements cost nothing more than a length check.
+*/
+
+// BeforeExecutionAddon is called before every instruction is fetched
+type BeforeExecutionAddon interface {
+	BeforeExecution()
+}
+
+// ReadAddon is called after every byte the CPU reads, including opcode fetches
+type ReadAddon interface {
+	OnRead(address uint16, value byte)
+}
+
+// WriteAddon is called after every byte the CPU writes
+type WriteAddon interface {
+	OnWrite(address uint16, value byte)
+}
+
+// InterruptAddon is called when the CPU enters an NMI, IRQ or BRK handler
+type InterruptAddon interface {
+	OnInterrupt(interruptType string)
+}
+
+// ResetAddon is called when the CPU is reset
+type ResetAddon interface {
+	OnReset()
+}
+
+// Registered addons sorted by the hooks they implement
+type addonHooks struct {
+	before    []BeforeExecutionAddon
+	read      []ReadAddon
+	write     []WriteAddon
+	interrupt []InterruptAddon
+	reset     []ResetAddon
+}
+
+func (h *addonHooks) add(addon Addon) {
+	if hook, ok := addon.(BeforeExecutionAddon); ok {
+		h.before = append(h.before, hook)
+	}
+	if hook, ok := addon.(ReadAddon); ok {
+		h.read = append(h.read, hook)
+	}
+	if hook, ok := addon.(WriteAddon); ok {
+		h.write = append(h.write, hook)
+	}
+	if hook, ok := addon.(InterruptAddon); ok {
+		h.interrupt = append(h.interrupt, hook)
+	}
+	if hook, ok := addon.(ResetAddon); ok {
+		h.reset = append(h.reset, hook)
+	}
+}
+
 type BaseAddon struct {
 	G6 *Go6502
 }
@@ -0,0 +1,118 @@
+package cpu
+
+import (
+	"fmt"
+	"github.com/edison-moreland/go6502/memory"
+	"github.com/edison-moreland/go6502/testingHelp"
+	"testing"
+)
+
+// Records every hook as it's called
+type recordingAddon struct {
+	BaseAddon
+	events []string
+}
+
+func (ra *recordingAddon) BeforeExecution() {
+	ra.events = append(ra.events, fmt.Sprintf("before %04X", ra.G6.PC))
+}
+
+func (ra *recordingAddon) AfterExecution() {
+	ra.events = append(ra.events, "after")
+}
+
+func (ra *recordingAddon) OnRead(address uint16, value byte) {
+	ra.events = append(ra.events, fmt.Sprintf("read %04X %02X", address, value))
+}
+
+func (ra *recordingAddon) OnWrite(address uint16, value byte) {
+	ra.events = append(ra.events, fmt.Sprintf("write %04X %02X", address, value))
+}
+
+func (ra *recordingAddon) OnInterrupt(interruptType string) {
+	ra.events = append(ra.events, "interrupt "+interruptType)
+}
+
+func (ra *recordingAddon) OnReset() {
+	ra.events = append(ra.events, "reset")
+}
+
+// Only implements writes
+type writeAddon struct {
+	BaseAddon
+	writes int
+}
+
+func (wa *writeAddon) OnWrite(address uint16, value byte) {
+	wa.writes++
+}
+
+func TestAddons_Hooks(t *testing.T) {
+	cpu := New()
+	recorder := &recordingAddon{}
+	cpu.RegisterAddons(recorder)
+
+	_ = cpu.Mem.WriteWord(0xFFFC, 0x1000)
+	_ = cpu.Mem.WriteWord(0xFFFA, 0x2000)
+	_ = cpu.Mem.WriteByte(0x1000, 0x85) // STA $10
+	_ = cpu.Mem.WriteByte(0x1001, 0x10)
+
+	testingHelp.NotNil(t, cpu.Reset())
+	cpu.A = 0x42
+	cpu.AssertNMI(0)
+	_, err := cpu.Step()
+	testingHelp.NotNil(t, err)
+
+	testingHelp.Equals(t, []string{
+		"read FFFC 00",
+		"read FFFD 10",
+		"reset",
+		"before 1000",
+		"read 1000 85",
+		"read 1001 10",
+		"write 0010 42",
+		"after",
+		"write 01FD 10",
+		"write 01FC 02",
+		"write 01FB 24",
+		"read FFFA 00",
+		"read FFFB 20",
+		"interrupt NMI",
+	}, recorder.events)
+}
+
+func TestAddons_OnlyImplementedHooks(t *testing.T) {
+	cpu := New()
+	writes := &writeAddon{}
+	cpu.RegisterAddons(writes)
+
+	testingHelp.Equals(t, 1, len(cpu.hooks.write))
+	testingHelp.Equals(t, 0, len(cpu.hooks.read))
+	testingHelp.Equals(t, 0, len(cpu.hooks.before))
+
+	runProgram(t, cpu, 0x1000, 2, 0x85, 0x10, 0xe6, 0x10) // STA $10, INC $10
+	testingHelp.Equals(t, 3, writes.writes)
+}
+
+func TestGo6502_Bus(t *testing.T) {
+	// With a bus set, the CPU goes through it instead of Mem
+	var register byte
+	bus := memory.NewMappedBus(&memory.Memory{})
+	testingHelp.NotNil(t, bus.Map(0xD000, 0xD000, memory.Device{
+		Read:  func(address uint16) byte { return register + 1 },
+		Write: func(address uint16, value byte) { register = value },
+	}))
+
+	cpu := New(WithBus(bus))
+	cpu.A = 0x41
+	program := []byte{0x8d, 0x00, 0xd0, 0xae, 0x00, 0xd0} // STA $D000, LDX $D000
+	for i, programByte := range program {
+		testingHelp.NotNil(t, bus.WriteByte(0x1000+uint16(i), programByte))
+	}
+
+	cpu.PC = 0x1000
+	testingHelp.NotNil(t, cpu.RunFor(2, UnitInstructions))
+	testingHelp.Equals(t, byte(0x41), register)
+	testingHelp.Equals(t, byte(0x42), cpu.X)
+	testingHelp.Equals(t, byte(0x00), cpu.Mem.Mem[0xD000])
+}
@@ -8,8 +8,66 @@ func zeroPageAddress(addr byte) (zeroPageAddr uint16, err error) {
 	return
 }
 
+func pageCrossed(a, b uint16) bool {
+	// Pages are 256 bytes long, so compare the high bytes
+	return a&0xFF00 != b&0xFF00
+}
+
+// AddressingMode identifies how an instruction finds its operand
+type AddressingMode uint8
+
+const (
+	ModeIMP AddressingMode = iota // Implied
+	ModeACC                       // Accumulator
+	ModeIMM
+	ModeZP
+	ModeZPX
+	ModeZPY
+	ModeIND
+	ModeINDX
+	ModeINDY
+	ModeABS
+	ModeABSX
+	ModeABSY
+	ModeREL
+
+	// 65C02 only
+	ModeZPIND
+	ModeINDABSX
+	ModeZPREL
+
+	modeCount
+)
+
+var modeNames = [modeCount]string{
+	ModeIMP:     "IMP",
+	ModeACC:     "ACC",
+	ModeIMM:     "IMM",
+	ModeZP:      "ZP",
+	ModeZPX:     "ZPX",
+	ModeZPY:     "ZPY",
+	ModeIND:     "IND",
+	ModeINDX:    "INDX",
+	ModeINDY:    "INDY",
+	ModeABS:     "ABS",
+	ModeABSX:    "ABSX",
+	ModeABSY:    "ABSY",
+	ModeREL:     "REL",
+	ModeZPIND:   "ZPIND",
+	ModeINDABSX: "INDABSX",
+	ModeZPREL:   "ZPREL",
+}
+
+func (m AddressingMode) String() string {
+	if m >= modeCount {
+		return "???"
+	}
+
+	return modeNames[m]
+}
+
 // Addressing modes
-type addressingMode func(g6 *Go6502) (addr uint16, err error)
+type addressingFunc func(g6 *Go6502) (addr uint16, err error)
 
 //	#	immediate	`OPC #$BB`	operand is byte BB
 func immediate(g6 *Go6502) (addr uint16, err error) {
@@ -19,7 +77,7 @@ func immediate(g6 *Go6502) (addr uint16, err error) {
 //	zpg		zeropage			`OPC $LL`	operand is zeropage address (hi-byte is zero, address = $00LL)
 func zeroPage(g6 *Go6502) (addr uint16, err error) {
 	// Use immediate byte as address for location in the 0th page of mem
-	immediateByte, err := g6.Mem.ReadByte(g6.PC + 1)
+	immediateByte, err := g6.readByte(g6.PC + 1)
 	if err != nil {
 		return 0, err
 	}
@@ -35,7 +93,7 @@ func zeroPage(g6 *Go6502) (addr uint16, err error) {
 //	zpg,X	zeropage, X-indexed	`OPC $LL,X`	operand is zeropage address; effective address is address incremented by X without carry **
 func zeroPageX(g6 *Go6502) (addr uint16, err error) {
 	// Use immediate byte as address for location in the 0th page of mem
-	immediateByte, err := g6.Mem.ReadByte(g6.PC + 1)
+	immediateByte, err := g6.readByte(g6.PC + 1)
 	if err != nil {
 		return 0, err
 	}
@@ -51,7 +109,7 @@ func zeroPageX(g6 *Go6502) (addr uint16, err error) {
 //	zpg,Y	zeropage, Y-indexed	`OPC $LL,Y`	operand is zeropage address; effective address is address incremented by Y without carry **
 func zeroPageY(g6 *Go6502) (addr uint16, err error) {
 	// Use immediate byte as address for location in the 0th page of mem
-	immediateByte, err := g6.Mem.ReadByte(g6.PC + 1)
+	immediateByte, err := g6.readByte(g6.PC + 1)
 	if err != nil {
 		return 0, err
 	}
@@ -67,7 +125,7 @@ func zeroPageY(g6 *Go6502) (addr uint16, err error) {
 //	abs		absolute	 		 OPC $LLHH		operand is address $HHLL *
 func absolute(g6 *Go6502) (addr uint16, err error) {
 	// Use next two byte as address
-	addr, err = g6.Mem.ReadWord(g6.PC + 1)
+	addr, err = g6.readWord(g6.PC + 1)
 	if err != nil {
 		return 0, err
 	}
@@ -77,31 +135,33 @@ func absolute(g6 *Go6502) (addr uint16, err error) {
 
 //	abs,X	absolute, X-indexed	 OPC $LLHH,X	operand is address; effective address is address incremented by X with carry **
 func absoluteX(g6 *Go6502) (addr uint16, err error) {
-	addr, err = g6.Mem.ReadWord(g6.PC + 1)
+	addr, err = g6.readWord(g6.PC + 1)
 	if err != nil {
 		return 0, err
 	}
 
-	addr += uint16(g6.X)
+	indexedAddr := addr + uint16(g6.X)
+	g6.pageCrossed = pageCrossed(addr, indexedAddr)
 
-	return
+	return indexedAddr, nil
 }
 
 //	abs,Y	absolute, Y-indexed	 OPC $LLHH,Y	operand is address; effective address is address incremented by Y with carry **
 func absoluteY(g6 *Go6502) (addr uint16, err error) {
-	addr, err = g6.Mem.ReadWord(g6.PC + 1)
+	addr, err = g6.readWord(g6.PC + 1)
 	if err != nil {
 		return 0, err
 	}
 
-	addr += uint16(g6.Y)
+	indexedAddr := addr + uint16(g6.Y)
+	g6.pageCrossed = pageCrossed(addr, indexedAddr)
 
-	return
+	return indexedAddr, nil
 }
 
 //	rel	relative	`OPC $BB`	branch target is PC + signed offset BB ***
 func relative(g6 *Go6502) (addr uint16, err error) {
-	immediateByte, err := g6.Mem.ReadByte(g6.PC + 1)
+	immediateByte, err := g6.readByte(g6.PC + 1)
 	if err != nil {
 		return 0, err
 	}
@@ -113,32 +173,43 @@ func relative(g6 *Go6502) (addr uint16, err error) {
 
 //	ind		indirect	 		 `OPC ($LLHH)`	operand is address; effective address is contents of word at address: C.w($HHLL)
 func indirect(g6 *Go6502) (addr uint16, err error) {
-	immediateWord, err := g6.Mem.ReadWord(g6.PC + 1)
+	immediateWord, err := g6.readWord(g6.PC + 1)
+	if err != nil {
+		return 0, err
+	}
+
+	if g6.isCMOS() {
+		addr, err = g6.readWord(immediateWord)
+		if err != nil {
+			return 0, err
+		}
+
+		return
+	}
+
+	// NMOS bug, the high byte of the pointer is never incremented.
+	// JMP ($10FF) reads $10FF and $1000 instead of $1100
+	low, err := g6.readByte(immediateWord)
 	if err != nil {
 		return 0, err
 	}
 
-	addr, err = g6.Mem.ReadWord(immediateWord)
+	high, err := g6.readByte(immediateWord&0xFF00 | uint16(uint8(immediateWord)+1))
 	if err != nil {
 		return 0, err
 	}
 
-	return
+	return memory.BytesToWord([2]byte{low, high})
 }
 
 //	X,ind	X-indexed, indirect	 `OPC ($LL,X)`	operand is zeropage address; effective address is word in (LL + X, LL + X + 1), inc. without carry: C.w($00LL + X)
 func indirectX(g6 *Go6502) (addr uint16, err error) {
-	immediateByte, err := g6.Mem.ReadByte(g6.PC + 1)
-	if err != nil {
-		return 0, err
-	}
-
-	addrLoc, err := zeroPageAddress(immediateByte + g6.X)
+	immediateByte, err := g6.readByte(g6.PC + 1)
 	if err != nil {
 		return 0, err
 	}
 
-	addr, err = g6.Mem.ReadWord(addrLoc)
+	addr, err = readZeroPageWord(g6, immediateByte+g6.X)
 	if err != nil {
 		return 0, err
 	}
@@ -148,38 +219,78 @@ func indirectX(g6 *Go6502) (addr uint16, err error) {
 
 //	ind,Y	indirect, Y-indexed	 `OPC ($LL),Y`	operand is zeropage address; effective address is word in (LL, LL + 1) incremented by Y with carry: C.w($00LL) + Y
 func indirectY(g6 *Go6502) (addr uint16, err error) {
-	immediateByte, err := g6.Mem.ReadByte(g6.PC + 1)
+	immediateByte, err := g6.readByte(g6.PC + 1)
 	if err != nil {
 		return 0, err
 	}
 
-	addrLoc, err := zeroPageAddress(immediateByte)
+	addr, err = readZeroPageWord(g6, immediateByte)
 	if err != nil {
 		return 0, err
 	}
 
-	addr, err = g6.Mem.ReadWord(addrLoc)
+	indexedAddr := addr + uint16(g6.Y)
+	g6.pageCrossed = pageCrossed(addr, indexedAddr)
+
+	return indexedAddr, nil
+}
+
+//	(zp)	zeropage indirect	 `OPC ($LL)`	operand is zeropage address; effective address is word in (LL, LL + 1): C.w($00LL) (65C02)
+func zeroPageIndirect(g6 *Go6502) (addr uint16, err error) {
+	immediateByte, err := g6.readByte(g6.PC + 1)
 	if err != nil {
 		return 0, err
 	}
 
-	addr += uint16(g6.Y)
+	return readZeroPageWord(g6, immediateByte)
+}
+
+//	(abs,X)	absolute X-indexed, indirect	`OPC ($LLHH,X)`	operand is address; effective address is word at address incremented by X: C.w($HHLL + X) (65C02)
+func absoluteIndirectX(g6 *Go6502) (addr uint16, err error) {
+	immediateWord, err := g6.readWord(g6.PC + 1)
+	if err != nil {
+		return 0, err
+	}
+
+	addr, err = g6.readWord(immediateWord + uint16(g6.X))
+	if err != nil {
+		return 0, err
+	}
 
 	return
 }
 
-var addressingModes = map[string]addressingMode{
-	"IMP":  nil, // Implied
-	"ACC":  nil, // Accumulator
-	"IMM":  immediate,
-	"ZP":   zeroPage,
-	"ZPX":  zeroPageX,
-	"ZPY":  zeroPageY,
-	"IND":  indirect,
-	"INDX": indirectX,
-	"INDY": indirectY,
-	"ABS":  absolute,
-	"ABSX": absoluteX,
-	"ABSY": absoluteY,
-	"REL":  relative,
+func readZeroPageWord(g6 *Go6502, zeroPageAddr byte) (word uint16, err error) {
+	// Pointers in zeropage wrap around to the start of zeropage instead of spilling into the stack
+	low, err := g6.readByte(uint16(zeroPageAddr))
+	if err != nil {
+		return 0, err
+	}
+
+	high, err := g6.readByte(uint16(zeroPageAddr + 1))
+	if err != nil {
+		return 0, err
+	}
+
+	return memory.BytesToWord([2]byte{low, high})
+}
+
+var addressingModes = [modeCount]addressingFunc{
+	ModeIMP:  nil, // Implied
+	ModeACC:  nil, // Accumulator
+	ModeIMM:  immediate,
+	ModeZP:   zeroPage,
+	ModeZPX:  zeroPageX,
+	ModeZPY:  zeroPageY,
+	ModeIND:  indirect,
+	ModeINDX: indirectX,
+	ModeINDY: indirectY,
+	ModeABS:  absolute,
+	ModeABSX: absoluteX,
+	ModeABSY: absoluteY,
+	ModeREL:  relative,
+
+	ModeZPIND:   zeroPageIndirect,
+	ModeINDABSX: absoluteIndirectX,
+	ModeZPREL:   zeroPage, // Bit branches use the zeropage byte, the offset is read by the handler
 }
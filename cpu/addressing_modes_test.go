@@ -0,0 +1,91 @@
+package cpu
+
+import (
+	"bytes"
+	"github.com/edison-moreland/go6502/memory"
+	"github.com/edison-moreland/go6502/testingHelp"
+	"testing"
+)
+
+func TestIndirect_PageWrapBug(t *testing.T) {
+	// JMP ($10FF) takes its high byte from $1000 on NMOS and $1100 on CMOS
+	setup := func(cpu *Go6502) {
+		_ = cpu.Mem.WriteByte(0x10FF, 0x34)
+		_ = cpu.Mem.WriteByte(0x1000, 0x12)
+		_ = cpu.Mem.WriteByte(0x1100, 0x56)
+	}
+
+	nmos := New()
+	setup(nmos)
+	runInstruction(t, nmos, 0x2000, 0x6c, 0xFF, 0x10)
+	testingHelp.Equals(t, uint16(0x1234), nmos.PC)
+
+	cmos := New(WithVariant(WDC65C02))
+	setup(cmos)
+	runInstruction(t, cmos, 0x2000, 0x6c, 0xFF, 0x10)
+	testingHelp.Equals(t, uint16(0x5634), cmos.PC)
+}
+
+func TestIndirectX_ZeroPageWrap(t *testing.T) {
+	// Pointer at $FF has its high byte at $00, not $0100
+	cpu := New()
+	cpu.X = 0x0F
+	_ = cpu.Mem.WriteByte(0x00FF, 0x00)
+	_ = cpu.Mem.WriteByte(0x0000, 0x20)
+	_ = cpu.Mem.WriteByte(0x0100, 0x30)
+	_ = cpu.Mem.WriteByte(0x2000, 0x42)
+
+	runInstruction(t, cpu, 0x1000, 0xa1, 0xF0)
+	testingHelp.Equals(t, byte(0x42), cpu.A)
+}
+
+func TestIndirectY_ZeroPageWrap(t *testing.T) {
+	cpu := New()
+	cpu.Y = 0x01
+	_ = cpu.Mem.WriteByte(0x00FF, 0x00)
+	_ = cpu.Mem.WriteByte(0x0000, 0x20)
+	_ = cpu.Mem.WriteByte(0x0100, 0x30)
+	_ = cpu.Mem.WriteByte(0x2001, 0x42)
+
+	runInstruction(t, cpu, 0x1000, 0xb1, 0xFF)
+	testingHelp.Equals(t, byte(0x42), cpu.A)
+}
+
+func TestModifyTarget_DoubleWrite(t *testing.T) {
+	// NMOS read-modify-write instructions write the old value back before the result,
+	// CMOS parts only write the result
+	tests := []struct {
+		name    string
+		program []byte
+		result  byte
+	}{
+		{"INC", []byte{0xee, 0x19, 0xd0}, 0x82}, // INC $D019
+		{"ASL", []byte{0x0e, 0x19, 0xd0}, 0x02}, // ASL $D019
+	}
+
+	for _, test := range tests {
+		for _, variant := range []Variant{NMOS6502, WDC65C02} {
+			cpu := New(WithVariant(variant))
+			register := byte(0x81)
+			var writes []byte
+
+			bus := memory.NewMappedBus(&cpu.Mem)
+			testingHelp.NotNil(t, bus.Map(0xD019, 0xD019, memory.Device{
+				Read: func(address uint16) byte { return register },
+				Write: func(address uint16, value byte) {
+					writes = append(writes, value)
+					register = value
+				},
+			}))
+			cpu.Bus = bus
+
+			runProgram(t, cpu, 0x1000, 1, test.program...)
+
+			expected := []byte{0x81, test.result}
+			if cpu.isCMOS() {
+				expected = []byte{test.result}
+			}
+			testingHelp.Assert(t, bytes.Equal(expected, writes), "%v on %v: expected writes %X, got %X", test.name, variant, expected, writes)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package cpu
+
+import (
+	"testing"
+	"time"
+)
+
+// Copies a block of memory, adding one to every byte, forever
+var benchmarkProgram = []byte{
+	0xa2, 0x00, // $0200 LDX #$00
+	0xbd, 0x00, 0x10, // $0202 LDA $1000,X
+	0x69, 0x01, // $0205 ADC #$01
+	0x9d, 0x00, 0x11, // $0207 STA $1100,X
+	0xe8,       // $020A INX
+	0xd0, 0xf5, // $020B BNE $0202
+	0x4c, 0x00, 0x02, // $020D JMP $0200
+}
+
+func BenchmarkGo6502_Emulation(b *testing.B) {
+	cpu := New()
+	for i, programByte := range benchmarkProgram {
+		_ = cpu.Mem.WriteByte(0x0200+uint16(i), programByte)
+	}
+
+	cpu.PC = 0x0200
+
+	b.ResetTimer()
+	start := time.Now()
+	if err := cpu.RunFor(uint64(b.N), UnitInstructions); err != nil {
+		b.Fatal(err)
+	}
+	elapsed := time.Since(start)
+
+	// Emulated clock speed, a real 6502 runs at 1-2MHz
+	b.Logf("%.2f MHz", float64(cpu.Cycles)/elapsed.Seconds()/1e6)
+}
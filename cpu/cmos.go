@@ -0,0 +1,246 @@
+package cpu
+
+/*
+65C02
+-----
+The CMOS 65C02 keeps every documented NMOS opcode and adds some more, the
+WDC part also includes the Rockwell bit instructions and WAI/STP.
+
+It also fixes a few NMOS bugs:
+- JMP ($xxFF) reads its high byte from the next page, costing an extra cycle
+- The decimal flag is cleared when entering an interrupt
+- N and Z are valid after decimal mode ADC/SBC
+- Undefined opcodes are NOPs with well defined sizes and timings
+
+http://www.6502.org/tutorials/65c02opcodes.html
+*/
+
+var CMOSInstructionSet = InstructionTable{}
+
+// Instructions that are new or have different timing on the 65C02
+var cmosInstructions = map[byte]Instruction{
+	0x6c: {0x6c, OpJMP, ModeIND, 3, 6, false},
+	0x1e: {0x1e, OpASL, ModeABSX, 3, 6, true},
+	0x3e: {0x3e, OpROL, ModeABSX, 3, 6, true},
+	0x5e: {0x5e, OpLSR, ModeABSX, 3, 6, true},
+	0x7e: {0x7e, OpROR, ModeABSX, 3, 6, true},
+	0x80: {0x80, OpBRA, ModeREL, 2, 2, false},
+	0xda: {0xda, OpPHX, ModeIMP, 1, 3, false},
+	0x5a: {0x5a, OpPHY, ModeIMP, 1, 3, false},
+	0xfa: {0xfa, OpPLX, ModeIMP, 1, 4, false},
+	0x7a: {0x7a, OpPLY, ModeIMP, 1, 4, false},
+	0x64: {0x64, OpSTZ, ModeZP, 2, 3, false},
+	0x74: {0x74, OpSTZ, ModeZPX, 2, 4, false},
+	0x9c: {0x9c, OpSTZ, ModeABS, 3, 4, false},
+	0x9e: {0x9e, OpSTZ, ModeABSX, 3, 5, false},
+	0x14: {0x14, OpTRB, ModeZP, 2, 5, false},
+	0x1c: {0x1c, OpTRB, ModeABS, 3, 6, false},
+	0x04: {0x04, OpTSB, ModeZP, 2, 5, false},
+	0x0c: {0x0c, OpTSB, ModeABS, 3, 6, false},
+	0x1a: {0x1a, OpINC, ModeACC, 1, 2, false},
+	0x3a: {0x3a, OpDEC, ModeACC, 1, 2, false},
+	0x89: {0x89, OpBIT, ModeIMM, 2, 2, false},
+	0x34: {0x34, OpBIT, ModeZPX, 2, 4, false},
+	0x3c: {0x3c, OpBIT, ModeABSX, 3, 4, true},
+	0x12: {0x12, OpORA, ModeZPIND, 2, 5, false},
+	0x32: {0x32, OpAND, ModeZPIND, 2, 5, false},
+	0x52: {0x52, OpEOR, ModeZPIND, 2, 5, false},
+	0x72: {0x72, OpADC, ModeZPIND, 2, 5, false},
+	0x92: {0x92, OpSTA, ModeZPIND, 2, 5, false},
+	0xb2: {0xb2, OpLDA, ModeZPIND, 2, 5, false},
+	0xd2: {0xd2, OpCMP, ModeZPIND, 2, 5, false},
+	0xf2: {0xf2, OpSBC, ModeZPIND, 2, 5, false},
+	0x7c: {0x7c, OpJMP, ModeINDABSX, 3, 6, false},
+	0xcb: {0xcb, OpWAI, ModeIMP, 1, 3, false},
+	0xdb: {0xdb, OpSTP, ModeIMP, 1, 3, false},
+}
+
+// Undefined opcodes that aren't single byte, single cycle NOPs
+var cmosNOPs = map[byte]Instruction{
+	0x02: {0x02, OpNOP, ModeIMM, 2, 2, false},
+	0x22: {0x22, OpNOP, ModeIMM, 2, 2, false},
+	0x42: {0x42, OpNOP, ModeIMM, 2, 2, false},
+	0x62: {0x62, OpNOP, ModeIMM, 2, 2, false},
+	0x82: {0x82, OpNOP, ModeIMM, 2, 2, false},
+	0xc2: {0xc2, OpNOP, ModeIMM, 2, 2, false},
+	0xe2: {0xe2, OpNOP, ModeIMM, 2, 2, false},
+	0x44: {0x44, OpNOP, ModeZP, 2, 3, false},
+	0x54: {0x54, OpNOP, ModeZPX, 2, 4, false},
+	0xd4: {0xd4, OpNOP, ModeZPX, 2, 4, false},
+	0xf4: {0xf4, OpNOP, ModeZPX, 2, 4, false},
+	0x5c: {0x5c, OpNOP, ModeABS, 3, 8, false},
+	0xdc: {0xdc, OpNOP, ModeABS, 3, 4, false},
+	0xfc: {0xfc, OpNOP, ModeABS, 3, 4, false},
+}
+
+func init() {
+	// Start with every documented NMOS instruction
+	for opcode, instruction := range InstructionSet {
+		if _, undocumented := UndocumentedInstructionSet[byte(opcode)]; !undocumented {
+			CMOSInstructionSet[opcode] = instruction
+		}
+	}
+
+	for opcode, instruction := range cmosInstructions {
+		CMOSInstructionSet[opcode] = instruction
+	}
+
+	// Rockwell bit instructions, the bit number is in the high nibble of the opcode
+	for bit := byte(0); bit < 8; bit++ {
+		bitInstructions := []Instruction{
+			{0x07 | bit<<4, OpRMB0 + Mnemonic(bit), ModeZP, 2, 5, false},
+			{0x87 | bit<<4, OpSMB0 + Mnemonic(bit), ModeZP, 2, 5, false},
+			{0x0f | bit<<4, OpBBR0 + Mnemonic(bit), ModeZPREL, 3, 5, false},
+			{0x8f | bit<<4, OpBBS0 + Mnemonic(bit), ModeZPREL, 3, 5, false},
+		}
+
+		for _, instruction := range bitInstructions {
+			CMOSInstructionSet[instruction.Opcode] = instruction
+		}
+
+		InstructionHandlers[OpRMB0+Mnemonic(bit)] = resetMemoryBit
+		InstructionHandlers[OpSMB0+Mnemonic(bit)] = setMemoryBit
+		InstructionHandlers[OpBBR0+Mnemonic(bit)] = branchOnBitReset
+		InstructionHandlers[OpBBS0+Mnemonic(bit)] = branchOnBitSet
+	}
+
+	// Everything left over is a NOP
+	for opcode := 0; opcode <= 0xFF; opcode++ {
+		if CMOSInstructionSet[opcode].Mnemonic != OpUnknown {
+			continue
+		}
+
+		if instruction, ok := cmosNOPs[byte(opcode)]; ok {
+			CMOSInstructionSet[opcode] = instruction
+		} else {
+			CMOSInstructionSet[opcode] = Instruction{byte(opcode), OpNOP, ModeIMP, 1, 1, false}
+		}
+	}
+
+	for mnemonic, handler := range cmosHandlers {
+		InstructionHandlers[mnemonic] = handler
+	}
+}
+
+func bitNumber(g6 *Go6502) byte {
+	// Rockwell bit instructions keep the bit number in bits 4-6 of the opcode
+	return (g6.CurrentInstruction.Opcode >> 4) & 0x07
+}
+
+func resetMemoryBit(g6 *Go6502, targetAddress *uint16) (err error) {
+	_, err = modifyTarget(g6, targetAddress, func(g6 *Go6502, val uint8) uint8 {
+		return val &^ (1 << bitNumber(g6))
+	})
+	return
+}
+
+func setMemoryBit(g6 *Go6502, targetAddress *uint16) (err error) {
+	_, err = modifyTarget(g6, targetAddress, func(g6 *Go6502, val uint8) uint8 {
+		return val | (1 << bitNumber(g6))
+	})
+	return
+}
+
+func branchOnBit(g6 *Go6502, targetAddress *uint16, branchIfSet bool) (err error) {
+	// Target address is the zeropage byte to test, the branch offset is the last byte of the instruction
+	val, err := g6.readByte(*targetAddress)
+	if err != nil {
+		return err
+	}
+
+	offset, err := g6.readByte(g6.PC + 2)
+	if err != nil {
+		return err
+	}
+
+	bitSet := val&(1<<bitNumber(g6)) != 0
+	if bitSet == branchIfSet {
+		g6.branch(g6.PC + g6.CurrentInstruction.Size + uint16(int8(offset)))
+	}
+
+	return nil
+}
+
+func branchOnBitReset(g6 *Go6502, targetAddress *uint16) (err error) {
+	return branchOnBit(g6, targetAddress, false)
+}
+
+func branchOnBitSet(g6 *Go6502, targetAddress *uint16) (err error) {
+	return branchOnBit(g6, targetAddress, true)
+}
+
+var cmosHandlers = map[Mnemonic]InstructionHandler{
+	// BRA, Branch always
+	OpBRA: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		g6.branch(*targetAddress)
+		return nil
+	},
+
+	// PHX, Push index X on stack
+	OpPHX: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		return g6.PushByteToStack(g6.X)
+	},
+
+	// PHY, Push index Y on stack
+	OpPHY: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		return g6.PushByteToStack(g6.Y)
+	},
+
+	// PLX, Pull index X from stack
+	OpPLX: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		g6.X, err = g6.PopByteOffStack()
+		if err != nil {
+			return err
+		}
+
+		setZeroNegativeFlags(g6, g6.X)
+		return nil
+	},
+
+	// PLY, Pull index Y from stack
+	OpPLY: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		g6.Y, err = g6.PopByteOffStack()
+		if err != nil {
+			return err
+		}
+
+		setZeroNegativeFlags(g6, g6.Y)
+		return nil
+	},
+
+	// STZ, Store zero in memory
+	OpSTZ: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		return g6.writeByte(*targetAddress, 0x00)
+	},
+
+	// TRB, Test and reset memory bits with accumulator
+	OpTRB: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		_, err = modifyTarget(g6, targetAddress, func(g6 *Go6502, val uint8) uint8 {
+			g6.Stat.Zero = g6.A&val == 0
+			return val &^ g6.A
+		})
+		return
+	},
+
+	// TSB, Test and set memory bits with accumulator
+	OpTSB: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		_, err = modifyTarget(g6, targetAddress, func(g6 *Go6502, val uint8) uint8 {
+			g6.Stat.Zero = g6.A&val == 0
+			return val | g6.A
+		})
+		return
+	},
+
+	// WAI, Wait for interrupt
+	OpWAI: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		g6.waiting = true
+		return nil
+	},
+
+	// STP, Stop the clock until reset
+	OpSTP: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		g6.halted = true
+		g6.shouldStopPCAutoIncrement = true
+		return nil
+	},
+}
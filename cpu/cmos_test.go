@@ -0,0 +1,132 @@
+package cpu
+
+import (
+	"github.com/edison-moreland/go6502/testingHelp"
+	"testing"
+)
+
+func newCMOS() *Go6502 {
+	cpu := New(WithVariant(WDC65C02))
+	cpu.SP = 0xFF
+	return cpu
+}
+
+func TestCMOSInstructionSet_Complete(t *testing.T) {
+	for opcode := 0; opcode <= 0xFF; opcode++ {
+		instruction := CMOSInstructionSet[opcode]
+		testingHelp.Assert(t, instruction.Mnemonic != OpUnknown, "opcode %#x does not exist", opcode)
+		testingHelp.Equals(t, byte(opcode), instruction.Opcode)
+
+		testingHelp.Assert(t, InstructionHandlers[instruction.Mnemonic] != nil, "%v has no handler", instruction.Mnemonic)
+	}
+}
+
+var cmosTests = []instructionTestData{
+	{"BRA", nil, []byte{0x80, 0x10}, func(t *testing.T, cpu *Go6502) {
+		testingHelp.Equals(t, uint16(0x1012), cpu.PC)
+		testingHelp.Equals(t, uint64(3), cpu.Cycles)
+	}},
+	{"PHX", func(cpu *Go6502) {
+		cpu.X = 0x42
+	}, []byte{0xda}, func(t *testing.T, cpu *Go6502) {
+		val, _ := cpu.PopByteOffStack()
+		testingHelp.Equals(t, byte(0x42), val)
+	}},
+	{"STZ absolute", func(cpu *Go6502) {
+		_ = cpu.Mem.WriteByte(0x2000, 0xFF)
+	}, []byte{0x9c, 0x00, 0x20}, func(t *testing.T, cpu *Go6502) {
+		val, _ := cpu.Mem.ReadByte(0x2000)
+		testingHelp.Equals(t, byte(0x00), val)
+	}},
+	{"TSB zeropage", func(cpu *Go6502) {
+		cpu.A = 0x0F
+		_ = cpu.Mem.WriteByte(0x0010, 0xF0)
+	}, []byte{0x04, 0x10}, func(t *testing.T, cpu *Go6502) {
+		val, _ := cpu.Mem.ReadByte(0x0010)
+		testingHelp.Equals(t, byte(0xFF), val)
+		testingHelp.Equals(t, true, cpu.Stat.Zero)
+	}},
+	{"TRB zeropage", func(cpu *Go6502) {
+		cpu.A = 0x0F
+		_ = cpu.Mem.WriteByte(0x0010, 0xFF)
+	}, []byte{0x14, 0x10}, func(t *testing.T, cpu *Go6502) {
+		val, _ := cpu.Mem.ReadByte(0x0010)
+		testingHelp.Equals(t, byte(0xF0), val)
+		testingHelp.Equals(t, false, cpu.Stat.Zero)
+	}},
+	{"INC accumulator", func(cpu *Go6502) {
+		cpu.A = 0xFF
+	}, []byte{0x1a}, func(t *testing.T, cpu *Go6502) {
+		testingHelp.Equals(t, byte(0x00), cpu.A)
+		testingHelp.Equals(t, true, cpu.Stat.Zero)
+	}},
+	{"BIT immediate leaves N and V alone", func(cpu *Go6502) {
+		cpu.A = 0x01
+	}, []byte{0x89, 0xC0}, func(t *testing.T, cpu *Go6502) {
+		testingHelp.Equals(t, Status{Zero: true}, cpu.Stat)
+	}},
+	{"LDA zeropage indirect wraps", func(cpu *Go6502) {
+		_ = cpu.Mem.WriteByte(0x00FF, 0x00)
+		_ = cpu.Mem.WriteByte(0x0000, 0x20)
+		_ = cpu.Mem.WriteByte(0x2000, 0x42)
+	}, []byte{0xb2, 0xFF}, func(t *testing.T, cpu *Go6502) {
+		testingHelp.Equals(t, byte(0x42), cpu.A)
+	}},
+	{"JMP absolute indexed indirect", func(cpu *Go6502) {
+		cpu.X = 0x02
+		_ = cpu.Mem.WriteWord(0x2002, 0x3000)
+	}, []byte{0x7c, 0x00, 0x20}, func(t *testing.T, cpu *Go6502) {
+		testingHelp.Equals(t, uint16(0x3000), cpu.PC)
+	}},
+	{"SMB3", nil, []byte{0xb7, 0x10}, func(t *testing.T, cpu *Go6502) {
+		val, _ := cpu.Mem.ReadByte(0x0010)
+		testingHelp.Equals(t, byte(0x08), val)
+	}},
+	{"RMB7", func(cpu *Go6502) {
+		_ = cpu.Mem.WriteByte(0x0010, 0xFF)
+	}, []byte{0x77, 0x10}, func(t *testing.T, cpu *Go6502) {
+		val, _ := cpu.Mem.ReadByte(0x0010)
+		testingHelp.Equals(t, byte(0x7F), val)
+	}},
+	{"BBS0 taken", func(cpu *Go6502) {
+		_ = cpu.Mem.WriteByte(0x0010, 0x01)
+	}, []byte{0x8f, 0x10, 0x05}, func(t *testing.T, cpu *Go6502) {
+		testingHelp.Equals(t, uint16(0x1008), cpu.PC)
+	}},
+	{"BBR0 not taken", func(cpu *Go6502) {
+		_ = cpu.Mem.WriteByte(0x0010, 0x01)
+	}, []byte{0x0f, 0x10, 0x05}, func(t *testing.T, cpu *Go6502) {
+		testingHelp.Equals(t, uint16(0x1003), cpu.PC)
+	}},
+	{"Undefined opcodes are NOPs", nil, []byte{0x5c, 0x00, 0x20}, func(t *testing.T, cpu *Go6502) {
+		testingHelp.Equals(t, uint16(0x1003), cpu.PC)
+		testingHelp.Equals(t, uint64(8), cpu.Cycles)
+	}},
+	{"Single byte NOPs", nil, []byte{0x03}, func(t *testing.T, cpu *Go6502) {
+		testingHelp.Equals(t, uint16(0x1001), cpu.PC)
+		testingHelp.Equals(t, uint64(1), cpu.Cycles)
+	}},
+}
+
+func TestCMOSInstructions(t *testing.T) {
+	for _, testData := range cmosTests {
+		t.Run(testData.description, func(t *testing.T) {
+			cpu := newCMOS()
+			if testData.setup != nil {
+				testData.setup(cpu)
+			}
+
+			runInstruction(t, cpu, 0x1000, testData.program...)
+			testData.check(t, cpu)
+		})
+	}
+}
+
+func TestCMOS_InterruptClearsDecimal(t *testing.T) {
+	cpu := newCMOS()
+	cpu.Stat.Decimal = true
+	cpu.AssertIRQ(0)
+
+	testingHelp.NotNil(t, cpu.HandleInterrupts())
+	testingHelp.Equals(t, false, cpu.Stat.Decimal)
+}
@@ -0,0 +1,202 @@
+package cpu
+
+import (
+	"github.com/edison-moreland/go6502/memory"
+	"github.com/pkg/errors"
+	"sync"
+	"sync/atomic"
+)
+
+/*
+Concurrency
+-----------
+Go6502 isn't safe to touch while it's running, except through these:
+
+StopEmulation: Stops the run at the next instruction boundary
+Pause:         Parks the run at the next instruction boundary, returns once it has
+Resume:        Lets a paused run carry on
+Inspect:       Calls a function on the CPU at the next instruction boundary
+Snapshot:      Copies the registers and memory at the next instruction boundary
+
+StopEmulation called between runs stops the next run before its first
+instruction.
+
+Requests set a flag the emulation loop checks after every instruction, so they
+cost an atomic load per instruction when nobody is using them. A paused run only
+wakes up for Resume or StopEmulation, cancelling Run's context has to wait.
+Addons already run between instructions, they shouldn't call Pause or Inspect.
+*/
+
+// A function waiting to be called on the CPU at an instruction boundary
+type controlRequest struct {
+	fn   func(g6 *Go6502)
+	done bool
+}
+
+type control struct {
+	mu   sync.Mutex
+	cond sync.Cond
+
+	// Set whenever the loop needs to look at the requests, read atomically
+	pending uint32
+	// Set by StopEmulation, read atomically
+	stop uint32
+
+	// Everything below is protected by mu
+	running  bool
+	paused   bool
+	parked   bool
+	requests []*controlRequest
+}
+
+func (c *control) wait() {
+	// sync.Cond has no constructor, the zero value CPU has to work
+	c.cond.L = &c.mu
+	c.cond.Wait()
+}
+
+// Snapshot is a consistent copy of the CPU taken between instructions
+type Snapshot struct {
+	Registers
+	Mem memory.Memory
+}
+
+func (g6 *Go6502) StopEmulation() {
+	// Safe to call from any goroutine, also wakes up a paused run so it can stop
+	atomic.StoreUint32(&g6.ctl.stop, 1)
+
+	g6.ctl.mu.Lock()
+	g6.ctl.cond.Broadcast()
+	g6.ctl.mu.Unlock()
+}
+
+func (g6 *Go6502) shouldStopEmulation() bool {
+	return atomic.LoadUint32(&g6.ctl.stop) != 0
+}
+
+func (g6 *Go6502) Pause() {
+	// Blocks until the CPU is parked between instructions, a CPU that isn't running starts its next run paused
+	c := &g6.ctl
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.paused = true
+	atomic.StoreUint32(&c.pending, 1)
+	for c.running && !c.parked {
+		c.wait()
+	}
+}
+
+func (g6 *Go6502) Resume() {
+	c := &g6.ctl
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.paused = false
+	if !c.running {
+		atomic.StoreUint32(&c.pending, 0)
+	}
+	c.cond.Broadcast()
+}
+
+func (g6 *Go6502) Paused() bool {
+	c := &g6.ctl
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.paused
+}
+
+func (g6 *Go6502) Running() bool {
+	c := &g6.ctl
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.running
+}
+
+func (g6 *Go6502) Inspect(fn func(g6 *Go6502)) {
+	// Calls fn between instructions and waits for it to finish, fn can read and write anything
+	c := &g6.ctl
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Nothing else is touching the CPU, call it straight away
+	if !c.running || c.parked {
+		fn(g6)
+		return
+	}
+
+	request := &controlRequest{fn: fn}
+	c.requests = append(c.requests, request)
+	atomic.StoreUint32(&c.pending, 1)
+
+	for !request.done && c.running {
+		c.wait()
+	}
+
+	// The run ended before getting to the request
+	if !request.done {
+		fn(g6)
+	}
+}
+
+func (g6 *Go6502) Snapshot() (snapshot Snapshot) {
+	g6.Inspect(func(g6 *Go6502) {
+		snapshot = Snapshot{g6.Registers(), g6.Mem}
+	})
+	return snapshot
+}
+
+func (g6 *Go6502) startRunning() (err error) {
+	c := &g6.ctl
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.running {
+		return errors.New("CPU is already running")
+	}
+	c.running = true
+	return nil
+}
+
+func (g6 *Go6502) stopRunning() {
+	c := &g6.ctl
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.running = false
+	c.cond.Broadcast()
+}
+
+func (g6 *Go6502) serviceControl() {
+	// Called by the emulation loop between instructions when a request is pending
+	c := &g6.ctl
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for {
+		for _, request := range c.requests {
+			request.fn(g6)
+			request.done = true
+		}
+		c.requests = nil
+		c.cond.Broadcast()
+
+		if !c.paused {
+			break
+		}
+		if g6.shouldStopEmulation() {
+			// Stopping ends the pause too, the next run starts normally
+			c.paused = false
+			break
+		}
+
+		// Park until resumed, requests can run directly on the CPU in the meantime
+		c.parked = true
+		c.wait()
+		c.parked = false
+	}
+
+	atomic.StoreUint32(&c.pending, 0)
+}
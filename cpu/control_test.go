@@ -0,0 +1,111 @@
+package cpu
+
+import (
+	"github.com/edison-moreland/go6502/testingHelp"
+	"testing"
+)
+
+func startLoopCPU(t *testing.T) (cpu *Go6502, finished chan error) {
+	cpu = newLoopCPU()
+	finished = make(chan error)
+	go func() {
+		finished <- cpu.RunUntil(func(g6 *Go6502) bool { return false })
+	}()
+
+	// Wait for the run to get going
+	for !cpu.Running() {
+	}
+	return cpu, finished
+}
+
+func TestGo6502_PauseResume(t *testing.T) {
+	cpu, finished := startLoopCPU(t)
+
+	cpu.Pause()
+	testingHelp.Equals(t, true, cpu.Paused())
+
+	// Nothing runs while paused
+	first := cpu.Snapshot()
+	second := cpu.Snapshot()
+	testingHelp.Equals(t, first.Instructions, second.Instructions)
+	testingHelp.Equals(t, first.X, second.X)
+
+	cpu.Resume()
+	testingHelp.Equals(t, false, cpu.Paused())
+	for cpu.Snapshot().Instructions == first.Instructions {
+	}
+
+	cpu.StopEmulation()
+	testingHelp.NotNil(t, <-finished)
+	testingHelp.Equals(t, false, cpu.Running())
+}
+
+func TestGo6502_StopWhilePaused(t *testing.T) {
+	cpu, finished := startLoopCPU(t)
+
+	cpu.Pause()
+	cpu.StopEmulation()
+	testingHelp.NotNil(t, <-finished)
+
+	// Stopping ended the pause, nothing is left waiting on it
+	testingHelp.Equals(t, false, cpu.Paused())
+	inspected := false
+	cpu.Inspect(func(g6 *Go6502) { inspected = true })
+	testingHelp.Equals(t, true, inspected)
+
+	before := cpu.Instructions
+	testingHelp.NotNil(t, cpu.RunFor(10, UnitInstructions))
+	testingHelp.Equals(t, before+10, cpu.Instructions)
+}
+
+func TestGo6502_StopBeforeRun(t *testing.T) {
+	// A stop that arrives before the run starts isn't lost
+	cpu := newLoopCPU()
+	cpu.StopEmulation()
+	testingHelp.NotNil(t, cpu.RunUntil(func(g6 *Go6502) bool { return false }))
+	testingHelp.Equals(t, uint64(0), cpu.Instructions)
+
+	// It only stops that one run
+	testingHelp.NotNil(t, cpu.RunFor(5, UnitInstructions))
+	testingHelp.Equals(t, uint64(5), cpu.Instructions)
+}
+
+func TestGo6502_Inspect(t *testing.T) {
+	cpu, finished := startLoopCPU(t)
+
+	// Changes made between instructions stick
+	cpu.Inspect(func(g6 *Go6502) {
+		g6.PC = 0x1000
+		_ = g6.Mem.WriteByte(0x1000, 0x02) // JAM
+	})
+
+	err := <-finished
+	testingHelp.Assert(t, err != nil, "expected the JAM to stop the run")
+
+	// Not running anymore, inspect runs straight away
+	var pc uint16
+	cpu.Inspect(func(g6 *Go6502) { pc = g6.PC })
+	testingHelp.Equals(t, uint16(0x1000), pc)
+}
+
+func TestGo6502_Snapshot(t *testing.T) {
+	cpu, finished := startLoopCPU(t)
+
+	snapshot := cpu.Snapshot()
+	cpu.StopEmulation()
+	testingHelp.NotNil(t, <-finished)
+
+	// Snapshot is taken between instructions, so PC is always at the start of one
+	testingHelp.Assert(t, snapshot.PC == 0x1000 || snapshot.PC == 0x1001, "PC %#v is mid instruction", snapshot.PC)
+	testingHelp.Equals(t, byte(0xe8), snapshot.Mem.Mem[0x1000])
+}
+
+func TestGo6502_AlreadyRunning(t *testing.T) {
+	cpu, finished := startLoopCPU(t)
+
+	_, err := cpu.Step()
+	testingHelp.Assert(t, err != nil, "Step should fail while the CPU is running")
+
+	cpu.StopEmulation()
+	testingHelp.NotNil(t, <-finished)
+}
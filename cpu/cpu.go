@@ -3,6 +3,8 @@ package cpu
 import (
 	"github.com/edison-moreland/go6502/memory"
 	"github.com/pkg/errors"
+	"io"
+	"sync/atomic"
 )
 
 /*
@@ -28,8 +30,11 @@ const BRK = "BRK"
 
 var interruptVectorLocations = map[string]uint16{NMI: 0xFFFA, RST: 0xFFFC, IRQ: 0xFFFE, BRK: 0xFFFE}
 
+// Number of cycles it takes the CPU to enter an interrupt handler
+const interruptCycles = 7
+
 func stackAddress(stackPointer byte) (address uint16, err error) {
-	address, err = memory.BytesToWord([2]byte{0x01, stackPointer})
+	address, err = memory.BytesToWord([2]byte{stackPointer, 0x01})
 	if err != nil {
 		return 0, errors.Wrapf(err, "Error converting stack pointer, %#v, to real address", stackPointer)
 	}
@@ -43,17 +48,66 @@ type Go6502 struct {
 	Stat        Status
 	Mem         memory.Memory
 
+	// Bus is what the CPU reads and writes through, Mem is used directly if it's nil
+	Bus memory.Bus
+
+	// DecimalMode selects NMOS or CMOS flag behaviour for decimal ADC/SBC
+	DecimalMode DecimalMode
+
+	// MagicConstant is ORed into A by the unstable XAA and LXA opcodes, it varies between chips
+	MagicConstant byte
+
+	// Cycles is the total number of clock cycles executed since emulation started
+	Cycles uint64
+	// Instructions is the total number of instructions executed since emulation started
+	Instructions uint64
+
+	// Extra cycles picked up by the current instruction (page crossings, taken branches)
+	pageCrossed bool
+	extraCycles uint64
+
+	// Software interrupts (RST, BRK) waiting to be handled
 	interruptOccurred    bool
 	currentInterruptType string
+	// Interrupt entered during the current step, if any
+	lastInterrupt string
+
+	// Hardware interrupt lines, one bit per source
+	irqLines, nmiLines uint64
+	nmiPending         bool
+	// I flag before the current instruction, CLI/SEI/PLP changes take an instruction to be seen
+	interruptDisableBefore bool
 
 	CurrentInstruction Instruction
 
 	shouldStopPCAutoIncrement bool
 
-	shouldStopEmulation bool
+	// Stop, pause and inspect requests from other goroutines
+	ctl control
+
+	// Recent steps that can be undone, nil unless history is enabled
+	history *history
+	// Trace output, nil unless tracing
+	tracer io.Writer
+
+	variant Variant
+	port    *IOPort
+
+	// Set by JAM and STP, only a reset will get the CPU going again
+	halted bool
+	// Set by WAI, the CPU sleeps until an interrupt comes along
+	waiting bool
 
 	enableAddons bool
 	addons       []Addon
+	hooks        addonHooks
+
+	// Device callbacks waiting for their cycle
+	events eventQueue
+
+	// Watchpoints, and the first one to ask for a stop during this step
+	watch    *memory.Watchpoints
+	watchHit *memory.Hit
 }
 
 func (g6 *Go6502) RegisterAddons(newAddons ...Addon) {
@@ -66,10 +120,99 @@ func (g6 *Go6502) RegisterAddons(newAddons ...Addon) {
 
 		for _, addon := range newAddons {
 			addon.Register(g6)
+			g6.hooks.add(addon)
 		}
 	}
 }
 
+func (g6 *Go6502) readByte(address uint16) (data byte, err error) {
+	// All CPU reads go through here so on-chip devices can intercept them
+	if g6.port != nil && address <= IOPortData {
+		data = g6.port.Read(address, g6.Cycles)
+	} else if g6.Bus != nil {
+		if data, err = g6.Bus.ReadByte(address); err != nil {
+			return 0, err
+		}
+	} else {
+		data = g6.Mem.Mem[address]
+	}
+
+	if g6.watch != nil || len(g6.hooks.read) != 0 {
+		g6.observeRead(address, data)
+	}
+
+	return data, nil
+}
+
+func (g6 *Go6502) observeRead(address uint16, data byte) {
+	// Kept out of readByte, most reads have nobody watching
+	if g6.watch != nil {
+		g6.checkWatch(memory.AccessRead, address, data, data)
+	}
+
+	for _, addon := range g6.hooks.read {
+		addon.OnRead(address, data)
+	}
+}
+
+func (g6 *Go6502) writeByte(address uint16, data byte) (err error) {
+	// All CPU writes go through here so on-chip devices can intercept them
+	if g6.port != nil && address <= IOPortData {
+		// The write still reaches RAM underneath the port
+		g6.port.Write(address, data, g6.Cycles)
+	}
+
+	var old byte
+	if g6.history != nil || g6.watch != nil {
+		old = g6.peekMemory(address)
+	}
+	if g6.history != nil {
+		g6.history.recordWrite(address, old)
+	}
+
+	if g6.Bus != nil {
+		err = g6.Bus.WriteByte(address, data)
+	} else {
+		err = g6.Mem.WriteByte(address, data)
+	}
+
+	if err != nil {
+		return g6.writeFailed(err)
+	}
+
+	if g6.watch != nil {
+		g6.checkWatch(memory.AccessWrite, address, old, data)
+	}
+
+	for _, addon := range g6.hooks.write {
+		addon.OnWrite(address, data)
+	}
+
+	return nil
+}
+
+func (g6 *Go6502) writeFailed(err error) error {
+	// Writes to read-only memory are blamed on the instruction doing them
+	if readOnly, ok := errors.Cause(err).(*memory.ReadOnlyError); ok {
+		readOnly.PC = g6.PC
+	}
+	return err
+}
+
+func (g6 *Go6502) readWord(address uint16) (data uint16, err error) {
+	low, err := g6.readByte(address)
+	if err != nil {
+		return 0, err
+	}
+
+	high, err := g6.readByte(address + 1)
+	if err != nil {
+		return 0, err
+	}
+
+	return memory.BytesToWord([2]byte{low, high})
+}
+
 func (g6 *Go6502) PushByteToStack(data byte) (err error) {
 	// Stack pointer always refers to an address in the second page
 	actualAddress, err := stackAddress(g6.SP)
@@ -77,7 +220,7 @@ func (g6 *Go6502) PushByteToStack(data byte) (err error) {
 		return errors.Wrapf(err, "Error pushing byte %#v to stack position %#v", data, g6.SP)
 	}
 
-	err = g6.Mem.WriteByte(actualAddress, data)
+	err = g6.writeByte(actualAddress, data)
 	if err != nil {
 		return errors.Wrapf(err, "Error pushing byte %#v to stack position %#v", data, g6.SP)
 	}
@@ -93,7 +236,7 @@ func (g6 *Go6502) PopByteOffStack() (data byte, err error) {
 		return 0, errors.Wrapf(err, "Error pulling byte off stack position %#v", g6.SP)
 	}
 
-	data, err = g6.Mem.ReadByte(actualAddress)
+	data, err = g6.readByte(actualAddress)
 	if err != nil {
 		return 0, errors.Wrapf(err, "Error pulling byte off stack position %#v", g6.SP)
 	}
@@ -105,16 +248,15 @@ func (g6 *Go6502) PushWordToStack(data uint16) (err error) {
 	// Split word into two bytes
 	bytes := memory.WordToBytes(data)
 
-	// Push first byte
-	err = g6.PushByteToStack(bytes[0])
+	// High byte goes first so the word ends up little endian in memory
+	err = g6.PushByteToStack(bytes[1])
 	if err != nil {
-		return errors.Wrapf(err, "Error pushing first byte of word %#v to stack", data)
+		return errors.Wrapf(err, "Error pushing high byte of word %#v to stack", data)
 	}
 
-	// Push second byte
-	err = g6.PushByteToStack(bytes[1])
+	err = g6.PushByteToStack(bytes[0])
 	if err != nil {
-		return errors.Wrapf(err, "Error pushing second byte of word %#v to stack", data)
+		return errors.Wrapf(err, "Error pushing low byte of word %#v to stack", data)
 	}
 
 	return nil
@@ -124,17 +266,15 @@ func (g6 *Go6502) PopWordOffStack() (data uint16, err error) {
 	// Create array to hold bytes so we can shove them into a word
 	bytes := [2]byte{}
 
-	// Bytes come off the stack in reverse order
-	// Pop second byte off
-	bytes[1], err = g6.PopByteOffStack()
+	// Bytes come off the stack in reverse order, low byte first
+	bytes[0], err = g6.PopByteOffStack()
 	if err != nil {
-		return 0, errors.Wrap(err, "Error popping first byte of word off stack")
+		return 0, errors.Wrap(err, "Error popping low byte of word off stack")
 	}
 
-	// Pop first byte off
-	bytes[0], err = g6.PopByteOffStack()
+	bytes[1], err = g6.PopByteOffStack()
 	if err != nil {
-		return 0, errors.Wrap(err, "Error popping second byte of word off stack")
+		return 0, errors.Wrap(err, "Error popping high byte of word off stack")
 	}
 
 	// Shove bytes into a word
@@ -150,7 +290,7 @@ func (g6 *Go6502) GetInterruptVector(interruptType string) (uint16, error) {
 	// Get interrupt vector from memory
 	location := interruptVectorLocations[interruptType]
 
-	vector, err := g6.Mem.ReadWord(location)
+	vector, err := g6.readWord(location)
 	if err != nil {
 		return 0, errors.Wrap(err, "Error retrieving interrupt vector from mem")
 	}
@@ -159,17 +299,33 @@ func (g6 *Go6502) GetInterruptVector(interruptType string) (uint16, error) {
 }
 
 func (g6 *Go6502) HandleInterrupts() (err error) {
+	// Services the highest priority pending interrupt: RST/BRK, then NMI, then IRQ unless masked
+	switch {
+	case g6.interruptOccurred:
+		return g6.enterInterrupt(g6.currentInterruptType)
+	case g6.nmiPending:
+		g6.nmiPending = false
+		return g6.enterInterrupt(NMI)
+	case g6.irqLines != 0 && !g6.irqMasked():
+		return g6.enterInterrupt(IRQ)
+	}
+
+	return nil
+}
+
+func (g6 *Go6502) enterInterrupt(interruptType string) (err error) {
 	/*
 		Handles all 4 types of interrupts, RST IRQ NMI BRK
 
 		if (NMI/IRQ/BRK):
 			PC -> Stack
 			Status -> Stack
+		if (RST):
+			SP -= 3, the pushes happen but are turned into reads
 
 		Set interrupt_disable
 		Load vector
 	*/
-	var interruptType = g6.currentInterruptType
 
 	if interruptType != RST {
 		// Every interrupt but rst pushes PC and Stat onto the stack
@@ -177,6 +333,9 @@ func (g6 *Go6502) HandleInterrupts() (err error) {
 		if err != nil {
 			return errors.Wrapf(err, "Error while handling non-RST interrupt, type %#v", interruptType)
 		}
+	} else {
+		// Reset goes through the same steps without writing anything
+		g6.SP -= 3
 	}
 
 	// Find location of interrupt handler
@@ -185,11 +344,34 @@ func (g6 *Go6502) HandleInterrupts() (err error) {
 		return errors.Wrapf(err, "Error while handling interrupt type %#v, couldn't get vector", interruptType)
 	}
 
+	// BRK pays for its cycles as an instruction, everything else takes 7 cycles to get going
+	if interruptType != BRK {
+		g6.Cycles += interruptCycles
+	}
+
 	// Start executing interrupt code
 	g6.Stat.InterruptDisable = true
+	if g6.isCMOS() {
+		g6.Stat.Decimal = false
+	}
 	g6.PC = interruptVector
 
+	if interruptType == RST {
+		for _, addon := range g6.hooks.reset {
+			addon.OnReset()
+		}
+	} else {
+		for _, addon := range g6.hooks.interrupt {
+			addon.OnInterrupt(interruptType)
+		}
+	}
+
 	// Clean up
+	g6.lastInterrupt = interruptType
+	if interruptType == RST {
+		g6.halted = false
+	}
+	g6.waiting = false
 	g6.currentInterruptType = ""
 	g6.interruptOccurred = false
 	return nil
@@ -212,43 +394,61 @@ func (g6 *Go6502) nonRSTInterrupt(interruptType string) (err error) {
 	return nil
 }
 
-func (g6 *Go6502) StopEmulation() {
-	g6.shouldStopEmulation = true
+func (g6 *Go6502) StopPCAutoIncrement() {
+	g6.shouldStopPCAutoIncrement = true
 }
 
-func (g6 *Go6502) StopPCAutoIncrement() {
+func (g6 *Go6502) branch(targetAddress uint16) {
+	// Taken branches cost an extra cycle, and another if the target is on a different page
+	nextInstruction := g6.PC + g6.CurrentInstruction.Size
+	g6.extraCycles++
+	if pageCrossed(nextInstruction, targetAddress) {
+		g6.extraCycles++
+	}
+
+	g6.PC = targetAddress
 	g6.shouldStopPCAutoIncrement = true
 }
 
 func (g6 *Go6502) ExecuteInstruction() (err error) {
+	g6.pageCrossed = false
+	g6.extraCycles = 0
+
+	instruction := &g6.CurrentInstruction
+	if instruction.Mode >= modeCount || instruction.Mnemonic >= mnemonicCount || InstructionHandlers[instruction.Mnemonic] == nil {
+		return &UnknownOpcodeError{PC: g6.PC, Opcode: instruction.Opcode, Registers: g6.Registers()}
+	}
+
 	// Find target for instruction
-	addressingFunc := addressingModes[g6.CurrentInstruction.Mode]
+	addressingFunc := addressingModes[instruction.Mode]
 	var targetAddress uint16
 	if addressingFunc != nil {
 		targetAddress, err = addressingFunc(g6)
 		if err != nil {
-			return errors.Wrapf(err, "Couldn't find target address for instruction (%v %v)", g6.CurrentInstruction.Mnemonic, g6.CurrentInstruction.Mode)
+			return errors.Wrapf(err, "Couldn't find target address for instruction (%v %v)", instruction.Mnemonic, instruction.Mode)
 		}
 	}
 
 	// Execute instruction
-	if instructionHandler, ok := InstructionHandlers[g6.CurrentInstruction.Mnemonic]; ok {
-		if addressingFunc != nil {
-			err = instructionHandler(g6, &targetAddress)
-		} else {
-			err = instructionHandler(g6, nil)
-		}
-
-		if err != nil {
-			return errors.Wrapf(err, "Error executing instruction (%v %v)", g6.CurrentInstruction.Mnemonic, g6.CurrentInstruction.Mode)
-		}
+	if addressingFunc != nil {
+		err = InstructionHandlers[instruction.Mnemonic](g6, &targetAddress)
 	} else {
-		return errors.Errorf("Instruction (%v %v) Has not been implemented", g6.CurrentInstruction.Mnemonic, g6.CurrentInstruction.Mode)
+		err = InstructionHandlers[instruction.Mnemonic](g6, nil)
+	}
+
+	if err != nil {
+		return errors.Wrapf(err, "Error executing instruction (%v %v)", instruction.Mnemonic, instruction.Mode)
+	}
+
+	// Count cycles
+	g6.Cycles += uint64(instruction.Cycles) + g6.extraCycles
+	if g6.pageCrossed && instruction.PageCrossPenalty {
+		g6.Cycles++
 	}
 
 	// Increment PC
 	if !g6.shouldStopPCAutoIncrement {
-		g6.PC += g6.CurrentInstruction.Size
+		g6.PC += instruction.Size
 	} else {
 		g6.shouldStopPCAutoIncrement = false
 	}
@@ -258,9 +458,7 @@ func (g6 *Go6502) ExecuteInstruction() (err error) {
 
 func (g6 *Go6502) StartEmulation() (err error) {
 	// Trigger RESET
-	g6.interruptOccurred = true
-	g6.currentInterruptType = RST
-	if err = g6.HandleInterrupts(); err != nil {
+	if err = g6.Reset(); err != nil {
 		return errors.Wrap(err, "Error handling RESET to start emulation")
 	}
 
@@ -275,6 +473,7 @@ func (g6 *Go6502) StartEmulation() (err error) {
 func (g6 *Go6502) StartEmulationAtAddress(startAddress uint16) (err error) {
 	// Starts emulation by setting ProgramCounter to start address
 	g6.PC = startAddress
+	g6.halted = false
 
 	if err = g6.emulationLoop(); err != nil {
 		return err
@@ -283,59 +482,161 @@ func (g6 *Go6502) StartEmulationAtAddress(startAddress uint16) (err error) {
 	return
 }
 
-func panicRecovery(err *error) {
-	// Captures a panic and turns it into an error
+func (g6 *Go6502) panicRecovery(err *error) {
+	// Captures a panic and turns it into a PanicError
 	// defer at the top of a panicky function with err being a named return
 	if r := recover(); r != nil {
 		// Return recovered error with stacktrace
-		*err = errors.Errorf("[RECOVERED PANIC]: %#v", r)
+		*err = errors.WithStack(&PanicError{
+			Value:     r,
+			PC:        g6.PC,
+			Opcode:    g6.CurrentInstruction.Opcode,
+			Registers: g6.Registers(),
+		})
+	}
+}
+
+func (g6 *Go6502) runAddons() {
+	// Run AfterExecution for each addon
+	if g6.enableAddons {
+		for _, addon := range g6.addons {
+			addon.AfterExecution()
+		}
 	}
 }
 
 func (g6 *Go6502) emulationLoop() (err error) {
-	defer panicRecovery(&err)
+	// Run until an addon stops emulation
+	return g6.run(nil)
+}
+
+func (g6 *Go6502) run(done func(g6 *Go6502) bool) (err error) {
+	if err = g6.startRunning(); err != nil {
+		return err
+	}
+	defer g6.stopRunning()
+	// A stop ends the run it arrives during, or the next one if it arrives between runs
+	defer atomic.StoreUint32(&g6.ctl.stop, 0)
+	defer g6.panicRecovery(&err)
+	g6.prepareRun()
+
+	// Emulation loop!
+	for {
+		// Pause and inspect requests from other goroutines
+		if atomic.LoadUint32(&g6.ctl.pending) != 0 {
+			g6.serviceControl()
+		}
+
+		if g6.shouldStopEmulation() {
+			return nil
+		}
+
+		if _, err = g6.step(); err != nil {
+			return err
+		}
+
+		if done != nil && done(g6) {
+			return nil
+		}
+	}
+}
+
+func (g6 *Go6502) prepareRun() {
 	g6.shouldStopPCAutoIncrement = false
 
 	// Turn off addons if none are registered
 	if (len(g6.addons) <= 0) && g6.enableAddons {
 		g6.enableAddons = false
 	}
+}
+
+func (g6 *Go6502) step() (result StepResult, err error) {
+	result.PC = g6.PC
+	startCycles := g6.Cycles
+	g6.lastInterrupt = ""
+	g6.watchHit = nil
+
+	if g6.history != nil {
+		g6.history.begin(g6)
+		defer g6.history.end()
+	}
 
-	// Emulation loop!
-	for !g6.shouldStopEmulation {
-		// Fetch instruction
-		opcode, err := g6.Mem.ReadByte(g6.PC)
-		if err != nil {
-			return errors.Wrap(err, "Error retrieving instruction")
+	// Sleep until an interrupt wakes us up
+	if g6.waiting {
+		result.Waiting = true
+		g6.Cycles++
+		g6.runAddons()
+		if g6.events.due(g6.Cycles) {
+			g6.runEvents()
 		}
 
-		// Decode instruction
-		if instruction, ok := InstructionSet[opcode]; ok {
-			g6.CurrentInstruction = instruction
-		} else {
-			return errors.Errorf("Opcode %#v does not exist", opcode)
+		// Any interrupt wakes the CPU, if IRQ is masked execution just carries on
+		if g6.nmiPending || g6.irqLines != 0 {
+			g6.waiting = false
 		}
 
-		// Execute instruction
-		if err = g6.ExecuteInstruction(); err != nil {
-			return errors.Wrap(err, "Error executing instruction")
+		if err = g6.HandleInterrupts(); err != nil {
+			return result, errors.Wrap(err, "Error handling interrupt while waiting")
 		}
 
-		// Run AfterExecution for each addon
-		if g6.enableAddons {
-			for _, addon := range g6.addons {
-				addon.AfterExecution()
-			}
+		result.Interrupt = g6.lastInterrupt
+		result.Cycles = g6.Cycles - startCycles
+		return result, g6.watchStop()
+	}
 
-		}
+	for _, addon := range g6.hooks.before {
+		addon.BeforeExecution()
+	}
 
-		// Handle interrupts
-		if g6.interruptOccurred == true {
-			err = g6.HandleInterrupts()
-			if err != nil {
-				return errors.Wrapf(err, "Error handling interrupt after instruction %#v", opcode)
-			}
+	if g6.tracer != nil {
+		if err = g6.trace(); err != nil {
+			return result, errors.Wrap(err, "Error writing trace")
 		}
 	}
-	return
+
+	// Fetch instruction
+	opcode, err := g6.readByte(g6.PC)
+	if err != nil {
+		return result, errors.Wrap(err, "Error retrieving instruction")
+	}
+	if g6.watch != nil {
+		g6.checkWatch(memory.AccessExecute, g6.PC, opcode, opcode)
+	}
+
+	// Decode instruction
+	g6.CurrentInstruction = g6.instructionSet()[opcode]
+	if g6.CurrentInstruction.Mnemonic == OpUnknown {
+		return result, &UnknownOpcodeError{PC: g6.PC, Opcode: opcode, Registers: g6.Registers()}
+	}
+	result.Instruction = g6.CurrentInstruction
+
+	// Execute instruction
+	g6.interruptDisableBefore = g6.Stat.InterruptDisable
+	if err = g6.ExecuteInstruction(); err != nil {
+		return result, errors.Wrap(err, "Error executing instruction")
+	}
+	g6.Instructions++
+
+	// Nothing but a reset can recover from a JAM
+	if g6.halted {
+		return result, &CPUHaltedError{PC: g6.PC, Opcode: opcode, Registers: g6.Registers()}
+	}
+
+	g6.runAddons()
+	if g6.events.due(g6.Cycles) {
+		g6.runEvents()
+	}
+
+	// Handle interrupts
+	err = g6.HandleInterrupts()
+	if err != nil {
+		return result, errors.Wrapf(err, "Error handling interrupt after instruction %#v", opcode)
+	}
+
+	result.Interrupt = g6.lastInterrupt
+	result.Cycles = g6.Cycles - startCycles
+	if g6.watchHit != nil {
+		return result, g6.watchStop()
+	}
+	return result, nil
 }
@@ -90,3 +90,90 @@ func TestGo6502_PushPopWordToStack(t *testing.T) {
 	testingHelp.Equals(t, testWords, actWords)
 
 }
+
+// Loads program at address and executes the first instruction
+func runInstruction(t *testing.T, cpu *Go6502, address uint16, program ...byte) {
+	for i, programByte := range program {
+		err := cpu.Mem.WriteByte(address+uint16(i), programByte)
+		testingHelp.NotNil(t, err)
+	}
+
+	cpu.PC = address
+	instruction := cpu.instructionSet()[program[0]]
+	testingHelp.Assert(t, instruction.Mnemonic != OpUnknown, "opcode %#v does not exist", program[0])
+
+	cpu.CurrentInstruction = instruction
+	testingHelp.NotNil(t, cpu.ExecuteInstruction())
+}
+
+type cycleTestData struct {
+	name    string
+	setup   func(cpu *Go6502)
+	program []byte
+	cycles  uint64
+}
+
+var cycleTests = []cycleTestData{
+	{"LDA immediate", nil, []byte{0xa9, 0x01}, 2},
+	{"LDA absolute,X same page", func(cpu *Go6502) { cpu.X = 0x01 }, []byte{0xbd, 0x00, 0x20}, 4},
+	{"LDA absolute,X page crossed", func(cpu *Go6502) { cpu.X = 0x01 }, []byte{0xbd, 0xFF, 0x20}, 5},
+	{"STA absolute,X page crossed", func(cpu *Go6502) { cpu.X = 0x01 }, []byte{0x9d, 0xFF, 0x20}, 5},
+	{"LDA indirect,Y page crossed", func(cpu *Go6502) {
+		cpu.Y = 0x10
+		_ = cpu.Mem.WriteWord(0x0040, 0x20F8)
+	}, []byte{0xb1, 0x40}, 6},
+	{"BNE not taken", func(cpu *Go6502) { cpu.Stat.Zero = true }, []byte{0xd0, 0x10}, 2},
+	{"BNE taken", nil, []byte{0xd0, 0x10}, 3},
+	{"BNE taken page crossed", nil, []byte{0xd0, 0x80}, 4},
+	{"JSR", nil, []byte{0x20, 0x00, 0x30}, 6},
+}
+
+func TestGo6502_Cycles(t *testing.T) {
+	for _, testData := range cycleTests {
+		cpu := new(Go6502)
+		cpu.SP = 0xFF
+		if testData.setup != nil {
+			testData.setup(cpu)
+		}
+
+		runInstruction(t, cpu, 0x1000, testData.program...)
+		testingHelp.Assert(t, cpu.Cycles == testData.cycles, "%v: expected %v cycles, got %v", testData.name, testData.cycles, cpu.Cycles)
+	}
+}
+
+// Loads program at address then runs it for a number of instructions
+func runProgram(t *testing.T, cpu *Go6502, address uint16, instructions uint64, program ...byte) {
+	for i, programByte := range program {
+		err := cpu.Mem.WriteByte(address+uint16(i), programByte)
+		testingHelp.NotNil(t, err)
+	}
+
+	cpu.PC = address
+	testingHelp.NotNil(t, cpu.RunFor(instructions, UnitInstructions))
+}
+
+func TestSetZeroNegativeFlags(t *testing.T) {
+	// N is bit 7 of the result, for every instruction and not just arithmetic
+	tests := []struct {
+		name           string
+		program        []byte
+		steps          uint64
+		negative, zero bool
+	}{
+		{"LDA #$80", []byte{0xa9, 0x80}, 1, true, false},
+		{"LDA #$FF", []byte{0xa9, 0xff}, 1, true, false},
+		{"LDA #$01", []byte{0xa9, 0x01}, 1, false, false},
+		{"LDA #$7F", []byte{0xa9, 0x7f}, 1, false, false},
+		{"LDA #$00", []byte{0xa9, 0x00}, 1, false, true},
+		{"LDX #$7F, INX", []byte{0xa2, 0x7f, 0xe8}, 2, true, false},
+		{"LDY #$80, DEY", []byte{0xa0, 0x80, 0x88}, 2, false, false},
+	}
+
+	for _, test := range tests {
+		cpu := New()
+		runProgram(t, cpu, 0x1000, test.steps, test.program...)
+
+		testingHelp.Assert(t, cpu.Stat.Negative == test.negative, "%v: expected N %v", test.name, test.negative)
+		testingHelp.Assert(t, cpu.Stat.Zero == test.zero, "%v: expected Z %v", test.name, test.zero)
+	}
+}
@@ -0,0 +1,107 @@
+package cpu
+
+/*
+Decimal mode
+------------
+When the decimal flag is set ADC and SBC treat their operands as binary-coded
+decimal, two digits per byte. The result of adding or subtracting valid BCD
+numbers is the same on every 6502, but the flags are not:
+
+NMOS: Z is taken from the binary result. ADC takes N and V from the result
+before the high digit is adjusted. SBC takes N, V, and Z from the binary result.
+
+CMOS: N and Z reflect the final decimal result, costing one extra cycle.
+
+Invalid BCD digits (A-F) give the same garbage results as real hardware.
+http://www.6502.org/tutorials/decimal_mode.html
+*/
+
+// DecimalMode selects how ADC and SBC set flags when the decimal flag is set
+type DecimalMode byte
+
+const (
+	// DecimalNMOS emulates the flag quirks of the original NMOS 6502
+	DecimalNMOS DecimalMode = iota
+	// DecimalCMOS emulates the 65C02, where N and Z are valid after decimal math
+	DecimalCMOS
+	// DecimalDisabled ignores the decimal flag, like the 2A03 in the NES
+	DecimalDisabled
+)
+
+func (g6 *Go6502) decimalEnabled() bool {
+	return g6.Stat.Decimal && g6.DecimalMode != DecimalDisabled
+}
+
+func addDecimal(g6 *Go6502, val uint8) {
+	carry := 0
+	if g6.Stat.Carry {
+		carry = 1
+	}
+
+	// Add low digits, adjusting into the high digit if they overflow
+	low := int(g6.A&0x0F) + int(val&0x0F) + carry
+	if low >= 0x0A {
+		low = ((low + 0x06) & 0x0F) + 0x10
+	}
+
+	// Add high digits, N and V are calculated before the high digit is adjusted
+	total := int(g6.A&0xF0) + int(val&0xF0) + low
+	g6.Stat.Negative = total&0x80 != 0
+	setOverflowFlag(g6, g6.A, val, uint8(total))
+
+	if total >= 0xA0 {
+		total += 0x60
+	}
+	g6.Stat.Carry = total >= 0x100
+
+	if g6.DecimalMode == DecimalCMOS {
+		setZeroNegativeFlags(g6, uint8(total))
+		g6.extraCycles++
+	} else {
+		// Zero flag comes from the binary sum
+		g6.Stat.Zero = uint8(int(g6.A)+int(val)+carry) == 0
+	}
+
+	g6.A = uint8(total)
+}
+
+func subtractDecimal(g6 *Go6502, val uint8) {
+	borrow := 0
+	if !g6.Stat.Carry {
+		borrow = 1
+	}
+
+	// NMOS flags are identical to a binary subtraction
+	binaryTotal := int(g6.A) - int(val) - borrow
+	g6.Stat.Carry = binaryTotal >= 0
+	setZeroNegativeFlags(g6, uint8(binaryTotal))
+	setOverflowFlag(g6, g6.A, ^val, uint8(binaryTotal))
+
+	var total int
+	low := int(g6.A&0x0F) - int(val&0x0F) - borrow
+	if g6.DecimalMode == DecimalCMOS {
+		// The 65C02 adjusts the binary result as a whole
+		total = binaryTotal
+		if total < 0 {
+			total -= 0x60
+		}
+		if low < 0 {
+			total -= 0x06
+		}
+
+		setZeroNegativeFlags(g6, uint8(total))
+		g6.extraCycles++
+	} else {
+		// Subtract low digits, borrowing from the high digit if they underflow
+		if low < 0 {
+			low = ((low - 0x06) & 0x0F) - 0x10
+		}
+
+		total = int(g6.A&0xF0) - int(val&0xF0) + low
+		if total < 0 {
+			total -= 0x60
+		}
+	}
+
+	g6.A = uint8(total)
+}
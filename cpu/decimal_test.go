@@ -0,0 +1,70 @@
+package cpu
+
+import (
+	"github.com/edison-moreland/go6502/testingHelp"
+	"testing"
+)
+
+type decimalTestData struct {
+	opcode      byte // ADC or SBC immediate
+	mode        DecimalMode
+	a, operand  byte
+	carry       bool
+	expA        byte
+	expStat     Status
+	description string
+}
+
+var decimalTests = []decimalTestData{
+	{0x69, DecimalNMOS, 0x12, 0x34, false, 0x46, Status{Decimal: true}, "12 + 34"},
+	{0x69, DecimalNMOS, 0x58, 0x46, true, 0x05, Status{Decimal: true, Carry: true, Negative: true, Overflow: true}, "58 + 46 + 1, NMOS"},
+	{0x69, DecimalNMOS, 0x99, 0x01, false, 0x00, Status{Decimal: true, Carry: true, Negative: true}, "99 + 01, NMOS"},
+	{0x69, DecimalCMOS, 0x99, 0x01, false, 0x00, Status{Decimal: true, Carry: true, Zero: true}, "99 + 01, CMOS"},
+	{0x69, DecimalNMOS, 0x79, 0x00, true, 0x80, Status{Decimal: true, Negative: true, Overflow: true}, "79 + 00 + 1"},
+	{0xe9, DecimalNMOS, 0x46, 0x12, true, 0x34, Status{Decimal: true, Carry: true}, "46 - 12"},
+	{0xe9, DecimalNMOS, 0x40, 0x13, true, 0x27, Status{Decimal: true, Carry: true}, "40 - 13"},
+	{0xe9, DecimalNMOS, 0x32, 0x02, false, 0x29, Status{Decimal: true, Carry: true}, "32 - 02 - 1"},
+	{0xe9, DecimalNMOS, 0x00, 0x01, true, 0x99, Status{Decimal: true, Negative: true}, "00 - 01, NMOS"},
+	{0xe9, DecimalCMOS, 0x00, 0x01, true, 0x99, Status{Decimal: true, Negative: true}, "00 - 01, CMOS"},
+	{0xe9, DecimalNMOS, 0x80, 0x01, true, 0x79, Status{Decimal: true, Carry: true, Overflow: true}, "80 - 01"},
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	for _, testData := range decimalTests {
+		cpu := new(Go6502)
+		cpu.DecimalMode = testData.mode
+		cpu.A = testData.a
+		cpu.Stat.Decimal = true
+		cpu.Stat.Carry = testData.carry
+
+		runInstruction(t, cpu, 0x1000, testData.opcode, testData.operand)
+		testingHelp.Assert(t, cpu.A == testData.expA, "%v: expected A=%#x, got %#x", testData.description, testData.expA, cpu.A)
+		testingHelp.Assert(t, cpu.Stat == testData.expStat, "%v: expected %+v, got %+v", testData.description, testData.expStat, cpu.Stat)
+	}
+}
+
+func TestDecimalArithmetic_CMOSExtraCycle(t *testing.T) {
+	cpu := new(Go6502)
+	cpu.DecimalMode = DecimalCMOS
+	cpu.Stat.Decimal = true
+
+	runInstruction(t, cpu, 0x1000, 0x69, 0x01)
+	testingHelp.Equals(t, uint64(3), cpu.Cycles)
+}
+
+func TestBinaryArithmetic(t *testing.T) {
+	// 0x50 + 0x50 overflows into a negative number
+	cpu := new(Go6502)
+	cpu.A = 0x50
+	runInstruction(t, cpu, 0x1000, 0x69, 0x50)
+	testingHelp.Equals(t, byte(0xA0), cpu.A)
+	testingHelp.Equals(t, Status{Negative: true, Overflow: true}, cpu.Stat)
+
+	// 0x50 - 0xB0 overflows the other way
+	cpu = new(Go6502)
+	cpu.A = 0x50
+	cpu.Stat.Carry = true
+	runInstruction(t, cpu, 0x1000, 0xe9, 0xB0)
+	testingHelp.Equals(t, byte(0xA0), cpu.A)
+	testingHelp.Equals(t, Status{Negative: true, Overflow: true}, cpu.Stat)
+}
@@ -0,0 +1,168 @@
+package cpu
+
+import (
+	"fmt"
+	"github.com/edison-moreland/go6502/memory"
+	"strings"
+)
+
+// Undocumented mnemonics nestest.log spells differently
+var nestestNames = map[Mnemonic]string{
+	OpISC:  "ISB",
+	OpUSBC: "SBC",
+}
+
+func (g6 *Go6502) peekByte(address uint16) byte {
+	// Reads memory like the CPU would, without any side effects
+	if g6.port != nil && address <= IOPortData {
+		return g6.port.Read(address, g6.Cycles)
+	}
+
+	return g6.peekMemory(address)
+}
+
+func (g6 *Go6502) peekMemory(address uint16) byte {
+	// Reads the bus without side effects, skipping the I/O port
+	if g6.Bus != nil {
+		return memory.Peek(g6.Bus, address)
+	}
+
+	return g6.Mem.Mem[address]
+}
+
+func (g6 *Go6502) pokeMemory(address uint16, data byte) {
+	// Writes the bus without any of the CPU's hooks
+	if g6.Bus != nil {
+		_ = g6.Bus.WriteByte(address, data)
+		return
+	}
+
+	g6.Mem.Mem[address] = data
+}
+
+func (g6 *Go6502) peekWord(address uint16) uint16 {
+	return uint16(g6.peekByte(address)) | uint16(g6.peekByte(address+1))<<8
+}
+
+func (g6 *Go6502) peekZeroPageWord(address byte) uint16 {
+	return uint16(g6.peekByte(uint16(address))) | uint16(g6.peekByte(uint16(address+1)))<<8
+}
+
+func (g6 *Go6502) Disassemble(address uint16) (text string, size uint16) {
+	// Disassembles the instruction at address, eg "LDA $0200,X"
+	return g6.disassemble(address, false)
+}
+
+func (g6 *Go6502) disassemble(address uint16, annotate bool) (text string, size uint16) {
+	// With annotate set, memory operands are followed by the effective address and value, nestest.log style:
+	// "LDA ($80),Y = 0200 @ 0204 = 5A". Annotations use the current registers so only make sense at PC
+	opcode := g6.peekByte(address)
+	instruction := g6.instructionSet()[opcode]
+	if instruction.Mnemonic == OpUnknown {
+		return fmt.Sprintf(".DB $%02X", opcode), 1
+	}
+
+	name, ok := nestestNames[instruction.Mnemonic]
+	if !ok {
+		name = instruction.Mnemonic.String()
+	}
+
+	operand := g6.peekByte(address + 1)
+	operandWord := g6.peekWord(address + 1)
+	next := address + instruction.Size
+
+	// Jumps go somewhere, they don't read or write
+	jump := instruction.Mnemonic == OpJMP || instruction.Mnemonic == OpJSR
+
+	var b strings.Builder
+	b.WriteString(name)
+	switch instruction.Mode {
+	case ModeACC:
+		b.WriteString(" A")
+	case ModeIMM:
+		fmt.Fprintf(&b, " #$%02X", operand)
+	case ModeZP:
+		fmt.Fprintf(&b, " $%02X", operand)
+		if annotate {
+			fmt.Fprintf(&b, " = %02X", g6.peekByte(uint16(operand)))
+		}
+	case ModeZPX, ModeZPY:
+		index, register := g6.X, "X"
+		if instruction.Mode == ModeZPY {
+			index, register = g6.Y, "Y"
+		}
+
+		fmt.Fprintf(&b, " $%02X,%v", operand, register)
+		if annotate {
+			effective := operand + index
+			fmt.Fprintf(&b, " @ %02X = %02X", effective, g6.peekByte(uint16(effective)))
+		}
+	case ModeABS:
+		fmt.Fprintf(&b, " $%04X", operandWord)
+		if annotate && !jump {
+			fmt.Fprintf(&b, " = %02X", g6.peekByte(operandWord))
+		}
+	case ModeABSX, ModeABSY:
+		index, register := g6.X, "X"
+		if instruction.Mode == ModeABSY {
+			index, register = g6.Y, "Y"
+		}
+
+		fmt.Fprintf(&b, " $%04X,%v", operandWord, register)
+		if annotate {
+			effective := operandWord + uint16(index)
+			fmt.Fprintf(&b, " @ %04X = %02X", effective, g6.peekByte(effective))
+		}
+	case ModeIND:
+		fmt.Fprintf(&b, " ($%04X)", operandWord)
+		if annotate {
+			target := g6.peekWord(operandWord)
+			if !g6.isCMOS() {
+				// Page wrap bug
+				target = uint16(g6.peekByte(operandWord)) | uint16(g6.peekByte(operandWord&0xFF00|uint16(uint8(operandWord)+1)))<<8
+			}
+			fmt.Fprintf(&b, " = %04X", target)
+		}
+	case ModeINDX:
+		fmt.Fprintf(&b, " ($%02X,X)", operand)
+		if annotate {
+			pointer := operand + g6.X
+			target := g6.peekZeroPageWord(pointer)
+			fmt.Fprintf(&b, " @ %02X = %04X = %02X", pointer, target, g6.peekByte(target))
+		}
+	case ModeINDY:
+		fmt.Fprintf(&b, " ($%02X),Y", operand)
+		if annotate {
+			target := g6.peekZeroPageWord(operand)
+			effective := target + uint16(g6.Y)
+			fmt.Fprintf(&b, " = %04X @ %04X = %02X", target, effective, g6.peekByte(effective))
+		}
+	case ModeREL:
+		fmt.Fprintf(&b, " $%04X", next+uint16(int8(operand)))
+	case ModeZPIND:
+		fmt.Fprintf(&b, " ($%02X)", operand)
+		if annotate {
+			target := g6.peekZeroPageWord(operand)
+			fmt.Fprintf(&b, " = %04X = %02X", target, g6.peekByte(target))
+		}
+	case ModeINDABSX:
+		fmt.Fprintf(&b, " ($%04X,X)", operandWord)
+		if annotate {
+			fmt.Fprintf(&b, " = %04X", g6.peekWord(operandWord+uint16(g6.X)))
+		}
+	case ModeZPREL:
+		offset := g6.peekByte(address + 2)
+		fmt.Fprintf(&b, " $%02X,$%04X", operand, next+uint16(int8(offset)))
+	}
+
+	return b.String(), instruction.Size
+}
+
+func (g6 *Go6502) isUndocumented(opcode byte) bool {
+	if g6.isCMOS() {
+		return false
+	}
+
+	_, undocumented := UndocumentedInstructionSet[opcode]
+	return undocumented
+}
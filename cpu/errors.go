@@ -0,0 +1,93 @@
+package cpu
+
+import (
+	"fmt"
+	"github.com/edison-moreland/go6502/memory"
+)
+
+/*
+Errors
+------
+Emulation stops with one of these when the CPU itself can't carry on. They're
+wrapped with github.com/pkg/errors, so errors.Cause gets back to them:
+
+	switch e := errors.Cause(err).(type) {
+	case *cpu.CPUHaltedError:
+		...
+	case *cpu.UnknownOpcodeError:
+		...
+	}
+
+errors.As from the standard library works too on Go 1.13 and later, but the
+module still supports Go 1.12.
+
+Errors from memory or addons are returned as they are, wrapped with context.
+*/
+
+// Registers is a copy of the CPU registers
+type Registers struct {
+	X, Y, A, SP  byte
+	PC           uint16
+	Stat         Status
+	Cycles       uint64
+	Instructions uint64
+}
+
+func (g6 *Go6502) Registers() Registers {
+	return Registers{
+		X: g6.X, Y: g6.Y, A: g6.A, SP: g6.SP,
+		PC:           g6.PC,
+		Stat:         g6.Stat,
+		Cycles:       g6.Cycles,
+		Instructions: g6.Instructions,
+	}
+}
+
+func (r Registers) String() string {
+	return fmt.Sprintf("PC:%04X A:%02X X:%02X Y:%02X P:%02X SP:%02X CYC:%d", r.PC, r.A, r.X, r.Y, r.Stat.AsByte(false), r.SP, r.Cycles)
+}
+
+// UnknownOpcodeError is an opcode the current variant can't execute
+type UnknownOpcodeError struct {
+	PC        uint16
+	Opcode    byte
+	Registers Registers
+}
+
+func (e *UnknownOpcodeError) Error() string {
+	return fmt.Sprintf("Opcode %#02x at %#04x does not exist (%v)", e.Opcode, e.PC, e.Registers)
+}
+
+// CPUHaltedError is a JAM or STP, only a reset gets the CPU going again
+type CPUHaltedError struct {
+	PC        uint16
+	Opcode    byte
+	Registers Registers
+}
+
+func (e *CPUHaltedError) Error() string {
+	return fmt.Sprintf("CPU halted by opcode %#02x at %#04x (%v)", e.Opcode, e.PC, e.Registers)
+}
+
+// PanicError is a panic during emulation, recovered and turned into an error
+type PanicError struct {
+	// Value passed to panic
+	Value     interface{}
+	PC        uint16
+	Opcode    byte
+	Registers Registers
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("[RECOVERED PANIC]: %#v, executing opcode %#02x at %#04x (%v)", e.Value, e.Opcode, e.PC, e.Registers)
+}
+
+// WatchpointError stops emulation after a watchpoint with Stop set triggers
+type WatchpointError struct {
+	Hit       memory.Hit
+	Registers Registers
+}
+
+func (e *WatchpointError) Error() string {
+	return fmt.Sprintf("Watchpoint hit: %v (%v)", e.Hit, e.Registers)
+}
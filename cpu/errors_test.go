@@ -0,0 +1,84 @@
+package cpu
+
+import (
+	"github.com/edison-moreland/go6502/memory"
+	"github.com/edison-moreland/go6502/testingHelp"
+	"github.com/pkg/errors"
+	"testing"
+)
+
+func TestCPUHaltedError(t *testing.T) {
+	cpu := New()
+	cpu.A = 0x42
+	_ = cpu.Mem.WriteByte(0x1000, 0x02) // JAM
+
+	err := cpu.StartEmulationAtAddress(0x1000)
+	err = errors.Wrap(err, "Wrapped by the caller")
+
+	halted, ok := errors.Cause(err).(*CPUHaltedError)
+	testingHelp.Assert(t, ok, "expected a CPUHaltedError, got %v", err)
+	testingHelp.Equals(t, uint16(0x1000), halted.PC)
+	testingHelp.Equals(t, byte(0x02), halted.Opcode)
+	testingHelp.Equals(t, byte(0x42), halted.Registers.A)
+}
+
+func TestUnknownOpcodeError(t *testing.T) {
+	cpu := New()
+	cpu.PC = 0x1000
+	cpu.CurrentInstruction = Instruction{Opcode: 0xff, Mnemonic: mnemonicCount}
+
+	err := cpu.ExecuteInstruction()
+
+	unknown, ok := errors.Cause(err).(*UnknownOpcodeError)
+	testingHelp.Assert(t, ok, "expected an UnknownOpcodeError, got %v", err)
+	testingHelp.Equals(t, byte(0xff), unknown.Opcode)
+	testingHelp.Equals(t, uint16(0x1000), unknown.PC)
+}
+
+// Panics after the first instruction
+type panicAddon struct {
+	BaseAddon
+}
+
+func (pa *panicAddon) AfterExecution() {
+	panic("addon blew up")
+}
+
+func TestPanicError(t *testing.T) {
+	cpu := New()
+	cpu.RegisterAddons(&panicAddon{})
+	_ = cpu.Mem.WriteByte(0x1000, 0xe8) // INX
+
+	err := cpu.StartEmulationAtAddress(0x1000)
+
+	panicked, ok := errors.Cause(err).(*PanicError)
+	testingHelp.Assert(t, ok, "expected a PanicError, got %v", err)
+	testingHelp.Equals(t, "addon blew up", panicked.Value)
+	testingHelp.Equals(t, byte(0xe8), panicked.Opcode)
+	testingHelp.Equals(t, byte(0x01), panicked.Registers.X)
+
+	_, ok = errors.Cause(err).(*CPUHaltedError)
+	testingHelp.Assert(t, !ok, "a panic isn't a halt")
+}
+
+func TestReadOnlyError(t *testing.T) {
+	cpu := New()
+	cpu.Mem.SetReadOnly(0xE000, 0xFFFF)
+	cpu.Mem.WritePolicy = memory.RejectWrites
+	cpu.A = 0x42
+
+	program := []byte{0xea, 0x8d, 0x00, 0xe0, 0xea} // NOP, STA $E000, NOP
+	for i, programByte := range program {
+		testingHelp.NotNil(t, cpu.Mem.WriteByte(0x1000+uint16(i), programByte))
+	}
+	cpu.PC = 0x1000
+
+	err := cpu.RunFor(3, UnitInstructions)
+
+	readOnly, ok := errors.Cause(err).(*memory.ReadOnlyError)
+	testingHelp.Assert(t, ok, "expected a ReadOnlyError, got %v", err)
+	testingHelp.Equals(t, uint16(0x1001), readOnly.PC)
+	testingHelp.Equals(t, uint16(0xE000), readOnly.Address)
+	testingHelp.Equals(t, byte(0x42), readOnly.Value)
+	testingHelp.Equals(t, byte(0x00), cpu.Mem.Mem[0xE000])
+}
@@ -0,0 +1,168 @@
+package cpu
+
+import "github.com/pkg/errors"
+
+/*
+History
+-------
+With history enabled every step records the CPU state before it ran and the
+old value of every byte it wrote. StepBack puts those back, undoing one step at
+a time.
+
+History is a ring buffer with a fixed number of steps, once it fills up the
+oldest steps are dropped. Each step costs a few dozen bytes plus 3 bytes per
+memory write, most instructions write one byte at most.
+
+Only writes made by the CPU during a step are recorded, writing to Mem
+directly, from an addon, or by calling CPU methods like PushByteToStack between
+steps can't be undone. Addon state isn't rewound either. With a Bus, writes are
+undone by writing the old value back through it, devices will see those writes.
+*/
+
+type memoryWrite struct {
+	address uint16
+	old     byte
+}
+
+type historyEntry struct {
+	cpu    cpuState
+	port   portState
+	writes []memoryWrite
+}
+
+type history struct {
+	entries []historyEntry
+	// Where the next step will be recorded, and how many steps are recorded
+	next, count int
+	// Step currently being recorded
+	recording *historyEntry
+}
+
+func (h *history) begin(g6 *Go6502) {
+	entry := &h.entries[h.next]
+	entry.cpu = g6.captureState()
+	if g6.port != nil {
+		entry.port = g6.port.captureState()
+	}
+	entry.writes = entry.writes[:0]
+
+	h.recording = entry
+	h.next = (h.next + 1) % len(h.entries)
+	if h.count < len(h.entries) {
+		h.count++
+	}
+}
+
+func (h *history) end() {
+	h.recording = nil
+}
+
+func (h *history) recordWrite(address uint16, old byte) {
+	// Writes made outside a step, like pushing to the stack directly, aren't part of any step
+	if h.recording != nil {
+		h.recording.writes = append(h.recording.writes, memoryWrite{address, old})
+	}
+}
+
+func (h *history) oldest() *historyEntry {
+	return &h.entries[(h.next-h.count+len(h.entries))%len(h.entries)]
+}
+
+func (h *history) clear() {
+	h.next, h.count = 0, 0
+	h.recording = nil
+}
+
+func (g6 *Go6502) clearHistory() {
+	// History from before a load or variant switch can't be undone into what replaced it
+	if g6.history != nil {
+		g6.history.clear()
+	}
+}
+
+func (g6 *Go6502) EnableHistory(steps int) {
+	// Starts recording the last steps, throws away anything recorded already
+	if steps <= 0 {
+		g6.history = nil
+		return
+	}
+
+	g6.history = &history{entries: make([]historyEntry, steps)}
+}
+
+func (g6 *Go6502) DisableHistory() {
+	g6.history = nil
+}
+
+func (g6 *Go6502) HistoryLen() int {
+	// Number of steps that can be undone
+	if g6.history == nil {
+		return 0
+	}
+
+	return g6.history.count
+}
+
+func (g6 *Go6502) StepBack() (err error) {
+	// Undoes the last step
+	if err = g6.startRunning(); err != nil {
+		return err
+	}
+	defer g6.stopRunning()
+
+	return g6.stepBack()
+}
+
+func (g6 *Go6502) RewindTo(instructionCount uint64) (err error) {
+	// Steps back until no more than instructionCount instructions have been executed
+	if err = g6.startRunning(); err != nil {
+		return err
+	}
+	defer g6.stopRunning()
+
+	if g6.Instructions <= instructionCount {
+		return nil
+	}
+
+	if g6.history == nil || g6.history.count == 0 || g6.history.oldest().cpu.Instructions > instructionCount {
+		return errors.Errorf("History doesn't go back to instruction %v", instructionCount)
+	}
+
+	for g6.Instructions > instructionCount {
+		if err = g6.stepBack(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (g6 *Go6502) stepBack() (err error) {
+	h := g6.history
+	if h == nil {
+		return errors.New("History is not enabled")
+	}
+	if h.count == 0 {
+		return errors.New("No history left to step back through")
+	}
+
+	h.next = (h.next - 1 + len(h.entries)) % len(h.entries)
+	h.count--
+	entry := &h.entries[h.next]
+
+	// Undo writes newest first, so a byte written twice ends up with its oldest value
+	for i := len(entry.writes) - 1; i >= 0; i-- {
+		g6.pokeMemory(entry.writes[i].address, entry.writes[i].old)
+	}
+
+	var lines byte
+	if g6.port != nil {
+		lines = g6.port.Lines(g6.Cycles)
+	}
+	g6.restoreState(entry.cpu)
+	if g6.port != nil {
+		g6.restorePort(entry.port, lines)
+	}
+
+	return nil
+}
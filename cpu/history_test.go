@@ -0,0 +1,147 @@
+package cpu
+
+import (
+	"bytes"
+	"github.com/edison-moreland/go6502/testingHelp"
+	"testing"
+)
+
+func newHistoryCPU(steps int) *Go6502 {
+	cpu, _ := newStateCPU(NMOS6502)
+	cpu.EnableHistory(steps)
+	return cpu
+}
+
+func TestGo6502_StepBack(t *testing.T) {
+	cpu := newHistoryCPU(100)
+
+	var snapshots []Snapshot
+	for i := 0; i < 20; i++ {
+		snapshots = append(snapshots, cpu.Snapshot())
+		testingHelp.NotNil(t, cpu.RunFor(1, UnitInstructions))
+	}
+	testingHelp.Equals(t, 20, cpu.HistoryLen())
+
+	for i := 19; i >= 0; i-- {
+		testingHelp.NotNil(t, cpu.StepBack())
+		testingHelp.Equals(t, snapshots[i], cpu.Snapshot())
+	}
+
+	err := cpu.StepBack()
+	testingHelp.Assert(t, err != nil, "expected stepping back past the start to fail")
+}
+
+func TestGo6502_StepBackInterrupt(t *testing.T) {
+	cpu := newHistoryCPU(100)
+	testingHelp.NotNil(t, cpu.RunFor(10, UnitInstructions))
+	before := cpu.Snapshot()
+
+	// Interrupt entry pushes to the stack, stepping back has to undo that too
+	cpu.AssertIRQ(0)
+	result, err := cpu.Step()
+	testingHelp.NotNil(t, err)
+	testingHelp.Equals(t, IRQ, result.Interrupt)
+
+	testingHelp.NotNil(t, cpu.StepBack())
+	testingHelp.Equals(t, before, cpu.Snapshot())
+	testingHelp.Equals(t, true, cpu.IRQAsserted())
+}
+
+func TestGo6502_RewindTo(t *testing.T) {
+	cpu := newHistoryCPU(50)
+	testingHelp.NotNil(t, cpu.RunFor(30, UnitInstructions))
+	at30 := cpu.Snapshot()
+
+	testingHelp.NotNil(t, cpu.RunFor(40, UnitInstructions))
+	testingHelp.Equals(t, 50, cpu.HistoryLen())
+
+	testingHelp.NotNil(t, cpu.RewindTo(30))
+	testingHelp.Equals(t, at30, cpu.Snapshot())
+
+	// Oldest steps were dropped when the buffer filled up
+	err := cpu.RewindTo(10)
+	testingHelp.Assert(t, err != nil, "expected rewinding past the history to fail")
+	testingHelp.Equals(t, at30, cpu.Snapshot())
+
+	// Runs the same way again after rewinding
+	testingHelp.NotNil(t, cpu.RunFor(40, UnitInstructions))
+	testingHelp.NotNil(t, cpu.RewindTo(30))
+	testingHelp.Equals(t, at30, cpu.Snapshot())
+}
+
+func TestGo6502_HistoryDisabled(t *testing.T) {
+	cpu := New()
+	testingHelp.Equals(t, 0, cpu.HistoryLen())
+
+	err := cpu.StepBack()
+	testingHelp.Assert(t, err != nil, "expected step back without history to fail")
+}
+
+func TestGo6502_HistoryClearedByLoadState(t *testing.T) {
+	cpu := newHistoryCPU(100)
+	testingHelp.NotNil(t, cpu.RunFor(5, UnitInstructions))
+
+	var state bytes.Buffer
+	testingHelp.NotNil(t, cpu.SaveState(&state))
+	testingHelp.NotNil(t, cpu.RunFor(5, UnitInstructions))
+	testingHelp.NotNil(t, cpu.LoadState(&state))
+	loaded := cpu.Snapshot()
+
+	// Steps from before the load can't be undone into the loaded machine
+	testingHelp.Equals(t, 0, cpu.HistoryLen())
+	err := cpu.StepBack()
+	testingHelp.Assert(t, err != nil, "expected step back after a load to fail")
+	testingHelp.Equals(t, "No history left to step back through", err.Error())
+	testingHelp.Equals(t, loaded, cpu.Snapshot())
+
+	// History carries on recording from the load
+	testingHelp.NotNil(t, cpu.RunFor(1, UnitInstructions))
+	testingHelp.NotNil(t, cpu.StepBack())
+	testingHelp.Equals(t, loaded, cpu.Snapshot())
+}
+
+func TestGo6502_HistoryClearedBySetVariant(t *testing.T) {
+	cpu := newHistoryCPU(100)
+	testingHelp.NotNil(t, cpu.RunFor(5, UnitInstructions))
+
+	cpu.SetVariant(NMOS6502)
+	testingHelp.Equals(t, 5, cpu.HistoryLen())
+
+	cpu.SetVariant(WDC65C02)
+	testingHelp.Equals(t, 0, cpu.HistoryLen())
+}
+
+func TestGo6502_StepBackPortOnChange(t *testing.T) {
+	cpu := New(WithVariant(MOS6510), WithHistory(10))
+	cpu.Port().PullUps = 0x17
+	var changes []byte
+	cpu.Port().OnChange = func(lines byte) { changes = append(changes, lines) }
+
+	runProgram(t, cpu, 0x1000, 2, 0xa9, 0x2f, 0x85, 0x00) // LDA #$2F, STA $00
+	testingHelp.Equals(t, []byte{0x10}, changes)
+	changes = nil
+
+	// Undoing the write makes them inputs again, back up on their pull-ups
+	testingHelp.NotNil(t, cpu.StepBack())
+	testingHelp.Equals(t, []byte{0x17}, changes)
+}
+
+func TestGo6502_HistoryWritesOutsideStep(t *testing.T) {
+	// Writes between steps aren't recorded, and mustn't crash
+	cpu := New(WithHistory(10))
+	cpu.SP = 0xFF
+	testingHelp.NotNil(t, cpu.PushByteToStack(0x42))
+	testingHelp.Equals(t, byte(0x42), cpu.Mem.Mem[0x01FF])
+
+	cpu.Stat.InterruptDisable = false
+	cpu.AssertIRQ(0)
+	testingHelp.NotNil(t, cpu.HandleInterrupts())
+	testingHelp.Equals(t, 0, cpu.HistoryLen())
+
+	// A step taken after still steps back on its own
+	cpu.ReleaseIRQ(0)
+	testingHelp.NotNil(t, cpu.RunFor(1, UnitInstructions))
+	testingHelp.NotNil(t, cpu.PushByteToStack(0x24))
+	testingHelp.NotNil(t, cpu.StepBack())
+	testingHelp.Equals(t, 0, cpu.HistoryLen())
+}
@@ -6,50 +6,174 @@ import "github.com/pkg/errors"
 func setZeroNegativeFlags(g6 *Go6502, val uint8) {
 	// Common combo of flags
 	g6.Stat.Zero = val == 0          // Set zero flag
-	g6.Stat.Negative = int8(val) > 0 // Set negative flag
+	g6.Stat.Negative = val&0x80 != 0 // Set negative flag
 }
 
 func setCarryFlag(g6 *Go6502, val uint16) {
 	g6.Stat.Carry = (val & 0xFF00) != 0
 }
 
-func setOverflowFlag(g6 *Go6502, val uint8, originalVal uint8) {
-	g6.Stat.Overflow = (originalVal & 0x80) != (val & 0x80)
+func setOverflowFlag(g6 *Go6502, val uint8, operand uint8, result uint8) {
+	// Overflow happens when both inputs share a sign and the result doesn't
+	g6.Stat.Overflow = (^(val^operand)&(val^result))&0x80 != 0
+}
+
+// Shifts and rotates //
+func shiftLeft(g6 *Go6502, val uint8) uint8 {
+	g6.Stat.Carry = val&0x80 != 0
+	val <<= 1
+
+	setZeroNegativeFlags(g6, val)
+	return val
+}
+
+func shiftRight(g6 *Go6502, val uint8) uint8 {
+	g6.Stat.Carry = val&0x01 != 0
+	val >>= 1
+
+	setZeroNegativeFlags(g6, val)
+	return val
+}
+
+func rotateLeft(g6 *Go6502, val uint8) uint8 {
+	// Old carry is rotated into bit 0
+	carryIn := uint8(0)
+	if g6.Stat.Carry {
+		carryIn = 0x01
+	}
+
+	g6.Stat.Carry = val&0x80 != 0
+	val = val<<1 | carryIn
+
+	setZeroNegativeFlags(g6, val)
+	return val
+}
+
+func rotateRight(g6 *Go6502, val uint8) uint8 {
+	// Old carry is rotated into bit 7
+	carryIn := uint8(0)
+	if g6.Stat.Carry {
+		carryIn = 0x80
+	}
+
+	g6.Stat.Carry = val&0x01 != 0
+	val = val>>1 | carryIn
+
+	setZeroNegativeFlags(g6, val)
+	return val
+}
+
+func modifyTarget(g6 *Go6502, targetAddress *uint16, modify func(g6 *Go6502, val uint8) uint8) (val uint8, err error) {
+	// Read-modify-write instructions can act on either memory or the accumulator
+	if g6.CurrentInstruction.Mode == ModeACC {
+		g6.A = modify(g6, g6.A)
+		return g6.A, nil
+	}
+
+	// Read byte from target address
+	val, err = g6.readByte(*targetAddress)
+	if err != nil {
+		return 0, err
+	}
+
+	// The NMOS 6502 writes the unmodified value back before writing the result,
+	// hardware registers like the VIC-II interrupt latch see both writes
+	if !g6.isCMOS() {
+		err = g6.writeByte(*targetAddress, val)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	val = modify(g6, val)
+
+	// Write byte to target address
+	err = g6.writeByte(*targetAddress, val)
+	if err != nil {
+		return 0, err
+	}
+
+	return val, nil
+}
+
+func increment(g6 *Go6502, val uint8) uint8 {
+	val++
+
+	setZeroNegativeFlags(g6, val)
+	return val
+}
+
+func decrement(g6 *Go6502, val uint8) uint8 {
+	val--
+
+	setZeroNegativeFlags(g6, val)
+	return val
+}
+
+func compare(g6 *Go6502, register uint8, val uint8) {
+	// Cast to uint16 to calculate carry
+	comp := uint16(register) - uint16(val)
+
+	setZeroNegativeFlags(g6, uint8(comp))
+	g6.Stat.Carry = register >= val
+}
+
+// Arithmetic //
+func addWithCarry(g6 *Go6502, val uint8) {
+	if g6.decimalEnabled() {
+		addDecimal(g6, val)
+		return
+	}
+
+	addBinary(g6, val)
+}
+
+func subtractWithCarry(g6 *Go6502, val uint8) {
+	if g6.decimalEnabled() {
+		subtractDecimal(g6, val)
+		return
+	}
+
+	// Subtracting is adding the ones complement, carry acts as an inverted borrow
+	addBinary(g6, ^val)
+}
+
+func addBinary(g6 *Go6502, val uint8) {
+	// Add it all up, cast to uint16 to calculate carry
+	total := uint16(g6.A) + uint16(val)
+	if g6.Stat.Carry {
+		total++
+	}
+
+	// Calculate flags
+	setCarryFlag(g6, total)
+	setZeroNegativeFlags(g6, uint8(total))
+	setOverflowFlag(g6, g6.A, val, uint8(total))
+
+	// Put back in accumulator
+	g6.A = uint8(total)
 }
 
 // Instruction Handlers
 type InstructionHandler func(g6 *Go6502, targetAddress *uint16) (err error)
 
-var InstructionHandlers = map[string]InstructionHandler{
+var InstructionHandlers = [mnemonicCount]InstructionHandler{
 	// ADC, Add memory to accumulator with carry
-	"ADC": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpADC: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		// Read byte from target address
-		val, err := g6.Mem.ReadByte(*targetAddress)
+		val, err := g6.readByte(*targetAddress)
 		if err != nil {
 			return err
 		}
 
-		// Add it all up, cast to uint16 to calculate carry
-		total := uint16(g6.A) + uint16(val)
-		if g6.Stat.Carry {
-			total++
-		}
-
-		// Calculate flags
-		setCarryFlag(g6, total)
-		setZeroNegativeFlags(g6, uint8(total))
-		setOverflowFlag(g6, uint8(total), g6.A)
-
-		// Put back in accumulator
-		g6.A = uint8(total)
-
+		addWithCarry(g6, val)
 		return
 	},
 
 	// AND, AND memory with accumulator
-	"AND": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpAND: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		// Read byte from target address
-		val, err := g6.Mem.ReadByte(*targetAddress)
+		val, err := g6.readByte(*targetAddress)
 		if err != nil {
 			return err
 		}
@@ -62,117 +186,100 @@ var InstructionHandlers = map[string]InstructionHandler{
 	},
 
 	// ASL, Shift left one bit
-	"ASL": func(g6 *Go6502, targetAddress *uint16) (err error) {
-		// This instruction can act on either memory or a register
-		var val uint8
-		if g6.CurrentInstruction.Mode == "ACC" {
-			val = g6.A
-		} else {
-			// Read byte from target address
-			val, err = g6.Mem.ReadByte(*targetAddress)
-			if err != nil {
-				return err
-			}
-		}
-
-		// Shift left by one bit, convert to uint16 to calculate carry
-		shiftedVal := uint16(val) << 1
-		val = uint8(shiftedVal)
-
-		if g6.CurrentInstruction.Mode == "ACC" {
-			g6.A = val
-		} else {
-			// Write byte to target address
-			err = g6.Mem.WriteByte(*targetAddress, val)
-			if err != nil {
-				return err
-			}
-		}
-
-		// Calculate flags
-		setZeroNegativeFlags(g6, val)
-		setCarryFlag(g6, shiftedVal)
-
+	OpASL: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		_, err = modifyTarget(g6, targetAddress, shiftLeft)
 		return
 	},
 
 	// Branch InstructionSet //
 	// BCC, Branch on carry clear
-	"BCC": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpBCC: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		if !g6.Stat.Carry {
-			g6.PC = *targetAddress
-			g6.shouldStopPCAutoIncrement = true
+			g6.branch(*targetAddress)
 		}
 		return nil
 	},
 
 	// BCS, Branch on carry set
-	"BCS": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpBCS: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		if g6.Stat.Carry {
-			g6.PC = *targetAddress
-			g6.shouldStopPCAutoIncrement = true
+			g6.branch(*targetAddress)
 		}
 		return nil
 	},
 
 	// BEQ, Branch on result zero
-	"BEQ": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpBEQ: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		if g6.Stat.Zero {
-			g6.PC = *targetAddress
-			g6.shouldStopPCAutoIncrement = true
+			g6.branch(*targetAddress)
 		}
 		return nil
 	},
 
 	// BMI, Branch on result minus
-	"BMI": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpBMI: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		if g6.Stat.Negative {
-			g6.PC = *targetAddress
-			g6.shouldStopPCAutoIncrement = true
+			g6.branch(*targetAddress)
 		}
 		return nil
 	},
 
 	// BNE, Branch on result not zero
-	"BNE": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpBNE: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		if !g6.Stat.Zero {
-			g6.PC = *targetAddress
-			g6.shouldStopPCAutoIncrement = true
+			g6.branch(*targetAddress)
 		}
 		return nil
 	},
 
 	// BPL, Branch on result plus
-	"BPL": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpBPL: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		if !g6.Stat.Negative {
-			g6.PC = *targetAddress
-			g6.shouldStopPCAutoIncrement = true
+			g6.branch(*targetAddress)
 		}
 		return nil
 	},
 
 	// BVC, Branch on overflow clear
-	"BVC": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpBVC: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		if !g6.Stat.Overflow {
-			g6.PC = *targetAddress
-			g6.shouldStopPCAutoIncrement = true
+			g6.branch(*targetAddress)
 		}
 		return nil
 	},
 
 	// BVS, Branch on overflow set
-	"BVS": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpBVS: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		if g6.Stat.Overflow {
-			g6.PC = *targetAddress
-			g6.shouldStopPCAutoIncrement = true
+			g6.branch(*targetAddress)
 		}
 		return nil
 	},
 
-	// BIT
+	// BIT, Test bits in memory with accumulator
+	OpBIT: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		// Read byte from target address
+		val, err := g6.readByte(*targetAddress)
+		if err != nil {
+			return err
+		}
+
+		g6.Stat.Zero = g6.A&val == 0
+
+		// Immediate mode (65C02 only) leaves N and V alone
+		if g6.CurrentInstruction.Mode != ModeIMM {
+			g6.Stat.Negative = val&0x80 != 0
+			g6.Stat.Overflow = val&0x40 != 0
+		}
+		return nil
+	},
 
 	// BRK
-	"BRK": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpBRK: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		// BRK skips the byte after it, the return address is BRK+2
+		g6.PC += 2
+		g6.shouldStopPCAutoIncrement = true
+
 		g6.interruptOccurred = true
 		g6.currentInterruptType = BRK
 		return nil
@@ -180,45 +287,70 @@ var InstructionHandlers = map[string]InstructionHandler{
 
 	// Clear InstructionSet //
 	// CLC, Clear Carry Flag
-	"CLC": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpCLC: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		g6.Stat.Carry = false
 		return nil
 	},
 
 	// CLD, Clear Decimal Mode
-	"CLD": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpCLD: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		g6.Stat.Decimal = false
 		return nil
 	},
 
 	// CLI, Clear Interrupt Disable
-	"CLI": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpCLI: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		g6.Stat.InterruptDisable = false
 		return nil
 	},
 
 	// CLV, Clear Overflow Flag
-	"CLV": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpCLV: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		g6.Stat.Overflow = false
 		return nil
 	},
 
 	// NOP
-	"NOP": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpNOP: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		return nil
 	},
 
-	// PHA
+	// Stack Instructions //
+	// PHA, Push accumulator on stack
+	OpPHA: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		return g6.PushByteToStack(g6.A)
+	},
+
+	// PLA, Pull accumulator from stack
+	OpPLA: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		g6.A, err = g6.PopByteOffStack()
+		if err != nil {
+			return err
+		}
+
+		setZeroNegativeFlags(g6, g6.A)
+		return nil
+	},
 
-	// PLA
+	// PHP, Push processor status on stack, B flag is always pushed set
+	OpPHP: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		return g6.PushByteToStack(g6.Stat.AsByte(true))
+	},
 
-	// PHP
+	// PLP, Pull processor status from stack
+	OpPLP: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		statusRegister, err := g6.PopByteOffStack()
+		if err != nil {
+			return err
+		}
 
-	// PLP
+		g6.Stat.FromByte(statusRegister)
+		return nil
+	},
 
 	// Return Instructions //
 	// RTI, Return from interrupt
-	"RTI": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpRTI: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		statusRegister, err := g6.PopByteOffStack()
 		if err != nil {
 			return errors.Wrap(err, "Couldn't retrieve status register from stack")
@@ -237,39 +369,40 @@ var InstructionHandlers = map[string]InstructionHandler{
 	},
 
 	// RTS, Return from subroutine
-	"RTS": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpRTS: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		returnAddress, err := g6.PopWordOffStack()
 		if err != nil {
 			return errors.Wrap(err, "Couldn't retrieve return address from stack")
 		}
 
-		g6.PC = returnAddress
+		// JSR pushed the address of its last byte
+		g6.PC = returnAddress + 1
 		g6.shouldStopPCAutoIncrement = true
 		return
 	},
 
 	// Set Flag Instructions //
 	// SEC, Set Carry
-	"SEC": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpSEC: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		g6.Stat.Carry = true
 		return nil
 	},
 
 	// SED, Set Decimal
-	"SED": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpSED: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		g6.Stat.Decimal = true
 		return nil
 	},
 
 	// SEI, Set Interrupt Disable
-	"SEI": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpSEI: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		g6.Stat.InterruptDisable = true
 		return nil
 	},
 
 	// Transfer InstructionSet //
 	// TAX, Transfer Accumulator to Index X
-	"TAX": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpTAX: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		g6.X = g6.A
 
 		setZeroNegativeFlags(g6, g6.A)
@@ -277,7 +410,7 @@ var InstructionHandlers = map[string]InstructionHandler{
 	},
 
 	// TAY, Transfer Accumulator to Index Y
-	"TAY": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpTAY: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		g6.Y = g6.A
 
 		setZeroNegativeFlags(g6, g6.A)
@@ -285,7 +418,7 @@ var InstructionHandlers = map[string]InstructionHandler{
 	},
 
 	// TSX, Transfer Stack Pointer to Index x
-	"TSX": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpTSX: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		g6.X = g6.SP
 
 		setZeroNegativeFlags(g6, g6.SP)
@@ -293,7 +426,7 @@ var InstructionHandlers = map[string]InstructionHandler{
 	},
 
 	// TXA, Transfer Index X to Accumulator
-	"TXA": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpTXA: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		g6.A = g6.X
 
 		setZeroNegativeFlags(g6, g6.X)
@@ -301,15 +434,14 @@ var InstructionHandlers = map[string]InstructionHandler{
 	},
 
 	// TXS, Transfer Index X to Stack Register
-	"TXS": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpTXS: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		// The only transfer that doesn't touch the flags
 		g6.SP = g6.X
-
-		setZeroNegativeFlags(g6, g6.X)
 		return nil
 	},
 
 	// TYA, Transfer Index Y to Accumulator
-	"TYA": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpTYA: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		g6.A = g6.Y
 
 		setZeroNegativeFlags(g6, g6.Y)
@@ -318,77 +450,50 @@ var InstructionHandlers = map[string]InstructionHandler{
 
 	// Compare InstructionSet //
 	// CMP, Compare memory with accumulator
-	"CMP": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpCMP: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		// Read byte from target address
-		val, err := g6.Mem.ReadByte(*targetAddress)
+		val, err := g6.readByte(*targetAddress)
 		if err != nil {
 			return err
 		}
 
-		// Cast to uint16 to calculate carry
-		comp := uint16(g6.A) - uint16(val)
-
-		setZeroNegativeFlags(g6, uint8(comp))
-		setCarryFlag(g6, comp)
+		compare(g6, g6.A, val)
 		return nil
 	},
 
 	// CPX Compare memory and index x
-	"CPX": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpCPX: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		// Read byte from target address
-		val, err := g6.Mem.ReadByte(*targetAddress)
+		val, err := g6.readByte(*targetAddress)
 		if err != nil {
 			return err
 		}
 
-		// Cast to uint16 to calculate carry
-		comp := uint16(g6.X) - uint16(val)
-
-		setZeroNegativeFlags(g6, uint8(comp))
-		setCarryFlag(g6, comp)
+		compare(g6, g6.X, val)
 		return nil
 	},
 
 	// CPY, Compare memory and index y
-	"CPY": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpCPY: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		// Read byte from target address
-		val, err := g6.Mem.ReadByte(*targetAddress)
+		val, err := g6.readByte(*targetAddress)
 		if err != nil {
 			return err
 		}
 
-		// Cast to uint16 to calculate carry
-		comp := uint16(g6.Y) - uint16(val)
-
-		setZeroNegativeFlags(g6, uint8(comp))
-		setCarryFlag(g6, comp)
+		compare(g6, g6.Y, val)
 		return nil
 	},
 
 	// Decrement InstructionSet //
 	// DEC, Decrement memory by one
-	"DEC": func(g6 *Go6502, targetAddress *uint16) (err error) {
-		// Read byte from target address
-		val, err := g6.Mem.ReadByte(*targetAddress)
-		if err != nil {
-			return err
-		}
-
-		// Decrement value
-		val--
-
-		// Write back to target address
-		err = g6.Mem.WriteByte(*targetAddress, val)
-		if err != nil {
-			return err
-		}
-
-		setZeroNegativeFlags(g6, val) // Set status register flags
+	OpDEC: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		_, err = modifyTarget(g6, targetAddress, decrement)
 		return
 	},
 
 	// DEX, Decrement Index X by one
-	"DEX": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpDEX: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		g6.X--
 
 		setZeroNegativeFlags(g6, g6.X)
@@ -396,7 +501,7 @@ var InstructionHandlers = map[string]InstructionHandler{
 	},
 
 	// DEY, Decrement Index Y by one
-	"DEY": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpDEY: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		g6.Y--
 
 		setZeroNegativeFlags(g6, g6.Y)
@@ -404,29 +509,14 @@ var InstructionHandlers = map[string]InstructionHandler{
 	},
 
 	// Increment Instructions //
-	// INC
-	"INC": func(g6 *Go6502, targetAddress *uint16) (err error) {
-		// Read byte from target address
-		val, err := g6.Mem.ReadByte(*targetAddress)
-		if err != nil {
-			return err
-		}
-
-		// Increment value
-		val++
-
-		// Write back to target address
-		err = g6.Mem.WriteByte(*targetAddress, val)
-		if err != nil {
-			return err
-		}
-
-		setZeroNegativeFlags(g6, val) // Set status register flags
+	// INC, Increment memory by one
+	OpINC: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		_, err = modifyTarget(g6, targetAddress, increment)
 		return
 	},
 
 	// INX
-	"INX": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpINX: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		g6.X++
 
 		setZeroNegativeFlags(g6, g6.X)
@@ -434,7 +524,7 @@ var InstructionHandlers = map[string]InstructionHandler{
 	},
 
 	// INY
-	"INY": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpINY: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		g6.Y++
 
 		setZeroNegativeFlags(g6, g6.Y)
@@ -442,9 +532,9 @@ var InstructionHandlers = map[string]InstructionHandler{
 	},
 
 	// EOR, Exclusive-OR memory with accumulator
-	"EOR": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpEOR: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		// Read byte from target address
-		val, err := g6.Mem.ReadByte(*targetAddress)
+		val, err := g6.readByte(*targetAddress)
 		if err != nil {
 			return err
 		}
@@ -457,16 +547,16 @@ var InstructionHandlers = map[string]InstructionHandler{
 
 	// Jump InstructionSet //
 	// JMP, Jump to new location
-	"JMP": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpJMP: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		g6.PC = *targetAddress
 		g6.shouldStopPCAutoIncrement = true
 		return
 	},
 
 	// JSR, Jump to new location saving return address
-	"JSR": func(g6 *Go6502, targetAddress *uint16) (err error) {
-		// Save address of next instruction for return
-		if err = g6.PushWordToStack(g6.PC + g6.CurrentInstruction.Size); err != nil {
+	OpJSR: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		// Save address of the last byte of JSR, RTS adds one
+		if err = g6.PushWordToStack(g6.PC + g6.CurrentInstruction.Size - 1); err != nil {
 			return err
 		}
 
@@ -477,9 +567,9 @@ var InstructionHandlers = map[string]InstructionHandler{
 
 	// Load InstructionSet //
 	// LDA, Load A with Memory
-	"LDA": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpLDA: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		// Read byte from target address
-		val, err := g6.Mem.ReadByte(*targetAddress)
+		val, err := g6.readByte(*targetAddress)
 		if err != nil {
 			return err
 		}
@@ -492,9 +582,9 @@ var InstructionHandlers = map[string]InstructionHandler{
 	},
 
 	// LDX, Load X with Memory
-	"LDX": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpLDX: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		// Read byte from target address
-		val, err := g6.Mem.ReadByte(*targetAddress)
+		val, err := g6.readByte(*targetAddress)
 		if err != nil {
 			return err
 		}
@@ -506,9 +596,9 @@ var InstructionHandlers = map[string]InstructionHandler{
 	},
 
 	// LDY, Load Y with Memory
-	"LDY": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpLDY: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		// Read byte from target address
-		val, err := g6.Mem.ReadByte(*targetAddress)
+		val, err := g6.readByte(*targetAddress)
 		if err != nil {
 			return err
 		}
@@ -520,9 +610,9 @@ var InstructionHandlers = map[string]InstructionHandler{
 	},
 
 	// ORA, OR memory with accumulator
-	"ORA": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpORA: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		// Read byte from target address
-		val, err := g6.Mem.ReadByte(*targetAddress)
+		val, err := g6.readByte(*targetAddress)
 		if err != nil {
 			return err
 		}
@@ -534,109 +624,42 @@ var InstructionHandlers = map[string]InstructionHandler{
 		return
 	},
 
+	// LSR, Shift one bit right (memory or accumulator)
+	OpLSR: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		_, err = modifyTarget(g6, targetAddress, shiftRight)
+		return
+	},
+
 	// Rotate Instructions
 	// ROL, Rotate one bit left (Memory or Accumulator)
-	"ROL": func(g6 *Go6502, targetAddress *uint16) (err error) {
-		// This instruction can act on either memory or a register
-		var val uint8
-		if g6.CurrentInstruction.Mode == "ACC" {
-			val = g6.A
-		} else {
-			// Read byte from target address
-			val, err = g6.Mem.ReadByte(*targetAddress)
-			if err != nil {
-				return err
-			}
-		}
-
-		// TODO: Rotate, not shift
-		// Shift left by one bit, convert to uint16 to calculate carry
-		shiftedVal := uint16(val) << 1
-		val = uint8(shiftedVal)
-
-		if g6.CurrentInstruction.Mode == "ACC" {
-			g6.A = val
-		} else {
-			// Write byte to target address
-			err = g6.Mem.WriteByte(*targetAddress, val)
-			if err != nil {
-				return err
-			}
-		}
-
-		// Calculate flags
-		setZeroNegativeFlags(g6, val)
-		setCarryFlag(g6, shiftedVal)
-
+	OpROL: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		_, err = modifyTarget(g6, targetAddress, rotateLeft)
 		return
 	},
 
 	// ROR, rotate one bit right (memory or accumulator)
-	"ROR": func(g6 *Go6502, targetAddress *uint16) (err error) {
-		// This instruction can act on either memory or a register
-		var val uint8
-		if g6.CurrentInstruction.Mode == "ACC" {
-			val = g6.A
-		} else {
-			// Read byte from target address
-			val, err = g6.Mem.ReadByte(*targetAddress)
-			if err != nil {
-				return err
-			}
-		}
-
-		// TODO: Rotate, not shift
-		// Shift left by one bit, convert to uint16 to calculate carry
-		shiftedVal := uint16(val) >> 1
-		val = uint8(shiftedVal)
-
-		if g6.CurrentInstruction.Mode == "ACC" {
-			g6.A = val
-		} else {
-			// Write byte to target address
-			err = g6.Mem.WriteByte(*targetAddress, val)
-			if err != nil {
-				return err
-			}
-		}
-
-		// Calculate flags
-		setZeroNegativeFlags(g6, val)
-		setCarryFlag(g6, shiftedVal)
-
+	OpROR: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		_, err = modifyTarget(g6, targetAddress, rotateRight)
 		return
 	},
 
 	// SBC, Subtract accumulator with memory
-	"SBC": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpSBC: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		// Read byte from target address
-		val, err := g6.Mem.ReadByte(*targetAddress)
+		val, err := g6.readByte(*targetAddress)
 		if err != nil {
 			return err
 		}
 
-		// Add it all up, cast to uint16 to calculate carry
-		total := uint16(g6.A) - uint16(val)
-		if g6.Stat.Carry {
-			total--
-		}
-
-		// Calculate flags
-		setCarryFlag(g6, total)
-		setZeroNegativeFlags(g6, uint8(total))
-		setOverflowFlag(g6, uint8(total), g6.A)
-
-		// Put back in accumulator
-		g6.A = uint8(total)
-
+		subtractWithCarry(g6, val)
 		return
 	},
 
 	// Store InstructionSet //
 	// STA, Store A in Memory
-	"STA": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpSTA: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		// Write byte to target address
-		err = g6.Mem.WriteByte(*targetAddress, g6.A)
+		err = g6.writeByte(*targetAddress, g6.A)
 		if err != nil {
 			return err
 		}
@@ -644,9 +667,9 @@ var InstructionHandlers = map[string]InstructionHandler{
 	},
 
 	// STX, Store X in Memory
-	"STX": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpSTX: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		// Write byte to target address
-		err = g6.Mem.WriteByte(*targetAddress, g6.X)
+		err = g6.writeByte(*targetAddress, g6.X)
 		if err != nil {
 			return err
 		}
@@ -654,9 +677,9 @@ var InstructionHandlers = map[string]InstructionHandler{
 	},
 
 	// STY, Store Y in Memory
-	"STY": func(g6 *Go6502, targetAddress *uint16) (err error) {
+	OpSTY: func(g6 *Go6502, targetAddress *uint16) (err error) {
 		// Write byte to target address
-		err = g6.Mem.WriteByte(*targetAddress, g6.Y)
+		err = g6.writeByte(*targetAddress, g6.Y)
 		if err != nil {
 			return err
 		}
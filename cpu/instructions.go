@@ -1,161 +1,170 @@
 package cpu
 
 type Instruction struct {
-	Opcode         byte
-	Mnemonic, Mode string
-	Size           uint16
+	Opcode   byte
+	Mnemonic Mnemonic
+	Mode     AddressingMode
+	Size     uint16
+
+	// Cycles is the base number of clock cycles the instruction takes
+	Cycles uint8
+	// PageCrossPenalty is set when crossing a page boundary while indexing costs an extra cycle
+	PageCrossPenalty bool
 }
 
-var InstructionSet = map[byte]Instruction{
-	0x69: {0x69, "ADC", "IMM", 2},
-	0x65: {0x65, "ADC", "ZP", 2},
-	0x75: {0x75, "ADC", "ZPX", 2},
-	0x6d: {0x6d, "ADC", "ABS", 3},
-	0x7d: {0x7d, "ADC", "ABSX", 3},
-	0x79: {0x79, "ADC", "ABSY", 3},
-	0x61: {0x61, "ADC", "INDX", 2},
-	0x71: {0x71, "ADC", "INDY", 2},
-	0x29: {0x29, "AND", "IMM", 2},
-	0x25: {0x25, "AND", "ZP", 2},
-	0x35: {0x35, "AND", "ZPX", 2},
-	0x2d: {0x2d, "AND", "ABS", 3},
-	0x3d: {0x3d, "AND", "ABSX", 3},
-	0x39: {0x39, "AND", "ABSY", 3},
-	0x21: {0x21, "AND", "INDX", 2},
-	0x31: {0x31, "AND", "INDY", 2},
-	0x0a: {0x0a, "ASL", "ACC", 1},
-	0x06: {0x06, "ASL", "ZP", 2},
-	0x16: {0x16, "ASL", "ZPX", 2},
-	0x0e: {0x0e, "ASL", "ABS", 3},
-	0x1e: {0x1e, "ASL", "ABSX", 3},
-	0x90: {0x90, "BCC", "REL", 2},
-	0xB0: {0xB0, "BCS", "REL", 2},
-	0xF0: {0xF0, "BEQ", "REL", 2},
-	0x30: {0x30, "BMI", "REL", 2},
-	0xD0: {0xD0, "BNE", "REL", 2},
-	0x10: {0x10, "BPL", "REL", 2},
-	0x50: {0x50, "BVC", "REL", 2},
-	0x70: {0x70, "BVS", "REL", 2},
-	0x24: {0x24, "BIT", "ZP", 2},
-	0x2c: {0x2c, "BIT", "ABS", 3},
-	0x00: {0x00, "BRK", "IMP", 1},
-	0x18: {0x18, "CLC", "IMP", 1},
-	0xd8: {0xd8, "CLD", "IMP", 1},
-	0x58: {0x58, "CLI", "IMP", 1},
-	0xb8: {0xb8, "CLV", "IMP", 1},
-	0xea: {0xea, "NOP", "IMP", 1},
-	0x48: {0x48, "PHA", "IMP", 1},
-	0x68: {0x68, "PLA", "IMP", 1},
-	0x08: {0x08, "PHP", "IMP", 1},
-	0x28: {0x28, "PLP", "IMP", 1},
-	0x40: {0x40, "RTI", "IMP", 1},
-	0x60: {0x60, "RTS", "IMP", 1},
-	0x38: {0x38, "SEC", "IMP", 1},
-	0xf8: {0xf8, "SED", "IMP", 1},
-	0x78: {0x78, "SEI", "IMP", 1},
-	0xaa: {0xaa, "TAX", "IMP", 1},
-	0x8a: {0x8a, "TXA", "IMP", 1},
-	0xa8: {0xa8, "TAY", "IMP", 1},
-	0x98: {0x98, "TYA", "IMP", 1},
-	0xba: {0xba, "TSX", "IMP", 1},
-	0x9a: {0x9a, "TXS", "IMP", 1},
-	0xc9: {0xc9, "CMP", "IMM", 2},
-	0xc5: {0xc5, "CMP", "ZP", 2},
-	0xd5: {0xd5, "CMP", "ZPX", 2},
-	0xcd: {0xcd, "CMP", "ABS", 3},
-	0xdd: {0xdd, "CMP", "ABSX", 3},
-	0xd9: {0xd9, "CMP", "ABSY", 3},
-	0xc1: {0xc1, "CMP", "INDX", 2},
-	0xd1: {0xd1, "CMP", "INDY", 2},
-	0xe0: {0xe0, "CPX", "IMM", 2},
-	0xe4: {0xe4, "CPX", "ZP", 2},
-	0xec: {0xec, "CPX", "ABS", 3},
-	0xc0: {0xc0, "CPY", "IMM", 2},
-	0xc4: {0xc4, "CPY", "ZP", 2},
-	0xcc: {0xcc, "CPY", "ABS", 3},
-	0xc6: {0xc6, "DEC", "ZP", 2},
-	0xd6: {0xd6, "DEC", "ZPX", 2},
-	0xce: {0xce, "DEC", "ABS", 3},
-	0xde: {0xde, "DEC", "ABSX", 3},
-	0xca: {0xca, "DEX", "IMP", 1},
-	0x88: {0x88, "DEY", "IMP", 1},
-	0xe8: {0xe8, "INX", "IMP", 1},
-	0xc8: {0xc8, "INY", "IMP", 1},
-	0x49: {0x49, "EOR", "IMM", 2},
-	0x45: {0x45, "EOR", "ZP", 2},
-	0x55: {0x55, "EOR", "ZPX", 2},
-	0x4d: {0x4d, "EOR", "ABS", 3},
-	0x5d: {0x5d, "EOR", "ABSX", 3},
-	0x59: {0x59, "EOR", "ABSY", 3},
-	0x41: {0x41, "EOR", "INDX", 2},
-	0x51: {0x51, "EOR", "INDY", 2},
-	0xe6: {0xe6, "INC", "ZP", 2},
-	0xf6: {0xf6, "INC", "ZPX", 2},
-	0xee: {0xee, "INC", "ABS", 3},
-	0xfe: {0xfe, "INC", "ABSX", 3},
-	0x4c: {0x4c, "JMP", "ABS", 3},
-	0x6c: {0x6c, "JMP", "IND", 3},
-	0x20: {0x20, "JSR", "ABS", 3},
-	0xa9: {0xa9, "LDA", "IMM", 2},
-	0xa5: {0xa5, "LDA", "ZP", 2},
-	0xb5: {0xb5, "LDA", "ZPX", 2},
-	0xad: {0xad, "LDA", "ABS", 3},
-	0xbd: {0xbd, "LDA", "ABSX", 3},
-	0xb9: {0xb9, "LDA", "ABSY", 3},
-	0xa1: {0xa1, "LDA", "INDX", 2},
-	0xb1: {0xb1, "LDA", "INDY", 2},
-	0xa2: {0xa2, "LDX", "IMM", 2},
-	0xa6: {0xa6, "LDX", "ZP", 2},
-	0xb6: {0xb6, "LDX", "ZPY", 2},
-	0xae: {0xae, "LDX", "ABS", 3},
-	0xbe: {0xbe, "LDX", "ABSY", 3},
-	0xa0: {0xa0, "LDY", "IMM", 2},
-	0xa4: {0xa4, "LDY", "ZP", 2},
-	0xb4: {0xb4, "LDY", "ZPX", 2},
-	0xac: {0xac, "LDY", "ABS", 3},
-	0xbc: {0xbc, "LDY", "ABSX", 3},
-	0x4a: {0x4a, "LSR", "ACC", 1},
-	0x46: {0x46, "LSR", "ZP", 2},
-	0x56: {0x56, "LSR", "ZPX", 2},
-	0x4e: {0x4e, "LSR", "ABS", 3},
-	0x5e: {0x5e, "LSR", "ABSX", 3},
-	0x09: {0x09, "ORA", "IMM", 2},
-	0x05: {0x05, "ORA", "ZP", 2},
-	0x15: {0x15, "ORA", "ZPX", 2},
-	0x0d: {0x0d, "ORA", "ABS", 3},
-	0x1d: {0x1d, "ORA", "ABSX", 3},
-	0x19: {0x19, "ORA", "ABSY", 3},
-	0x01: {0x01, "ORA", "INDX", 2},
-	0x11: {0x11, "ORA", "INDY", 2},
-	0x2a: {0x2a, "ROL", "ACC", 1},
-	0x26: {0x26, "ROL", "ZP", 2},
-	0x36: {0x36, "ROL", "ZPX", 2},
-	0x2e: {0x2e, "ROL", "ABS", 3},
-	0x3e: {0x3e, "ROL", "ABSX", 3},
-	0x6a: {0x6a, "ROR", "ACC", 1},
-	0x66: {0x66, "ROR", "ZP", 2},
-	0x76: {0x76, "ROR", "ZPX", 2},
-	0x7e: {0x7e, "ROR", "ABS", 3},
-	0x6e: {0x6e, "ROR", "ABSX", 3},
-	0xe9: {0xe9, "SBC", "IMM", 2},
-	0xe5: {0xe5, "SBC", "ZP", 2},
-	0xf5: {0xf5, "SBC", "ZPX", 2},
-	0xed: {0xed, "SBC", "ABS", 3},
-	0xfd: {0xfd, "SBC", "ABSX", 3},
-	0xf9: {0xf9, "SBC", "ABSY", 3},
-	0xe1: {0xe1, "SBC", "INDX", 2},
-	0xf1: {0xf1, "SBC", "INDY", 2},
-	0x85: {0x85, "STA", "ZP", 2},
-	0x95: {0x95, "STA", "ZPX", 2},
-	0x8d: {0x8d, "STA", "ABS", 3},
-	0x9d: {0x9d, "STA", "ABSX", 3},
-	0x99: {0x99, "STA", "ABSY", 3},
-	0x81: {0x81, "STA", "INDX", 2},
-	0x91: {0x91, "STA", "INDY", 2},
-	0x86: {0x86, "STX", "ZP", 2},
-	0x96: {0x96, "STX", "ZPY", 2},
-	0x8e: {0x8e, "STX", "ABS", 3},
-	0x84: {0x84, "STY", "ZP", 2},
-	0x94: {0x94, "STY", "ZPX", 2},
-	0x8c: {0x8c, "STY", "ABS", 3},
+// InstructionTable decodes every possible opcode, unused opcodes are left as OpUnknown
+type InstructionTable [256]Instruction
+
+var InstructionSet = InstructionTable{
+	0x69: {0x69, OpADC, ModeIMM, 2, 2, false},
+	0x65: {0x65, OpADC, ModeZP, 2, 3, false},
+	0x75: {0x75, OpADC, ModeZPX, 2, 4, false},
+	0x6d: {0x6d, OpADC, ModeABS, 3, 4, false},
+	0x7d: {0x7d, OpADC, ModeABSX, 3, 4, true},
+	0x79: {0x79, OpADC, ModeABSY, 3, 4, true},
+	0x61: {0x61, OpADC, ModeINDX, 2, 6, false},
+	0x71: {0x71, OpADC, ModeINDY, 2, 5, true},
+	0x29: {0x29, OpAND, ModeIMM, 2, 2, false},
+	0x25: {0x25, OpAND, ModeZP, 2, 3, false},
+	0x35: {0x35, OpAND, ModeZPX, 2, 4, false},
+	0x2d: {0x2d, OpAND, ModeABS, 3, 4, false},
+	0x3d: {0x3d, OpAND, ModeABSX, 3, 4, true},
+	0x39: {0x39, OpAND, ModeABSY, 3, 4, true},
+	0x21: {0x21, OpAND, ModeINDX, 2, 6, false},
+	0x31: {0x31, OpAND, ModeINDY, 2, 5, true},
+	0x0a: {0x0a, OpASL, ModeACC, 1, 2, false},
+	0x06: {0x06, OpASL, ModeZP, 2, 5, false},
+	0x16: {0x16, OpASL, ModeZPX, 2, 6, false},
+	0x0e: {0x0e, OpASL, ModeABS, 3, 6, false},
+	0x1e: {0x1e, OpASL, ModeABSX, 3, 7, false},
+	0x90: {0x90, OpBCC, ModeREL, 2, 2, false},
+	0xB0: {0xB0, OpBCS, ModeREL, 2, 2, false},
+	0xF0: {0xF0, OpBEQ, ModeREL, 2, 2, false},
+	0x30: {0x30, OpBMI, ModeREL, 2, 2, false},
+	0xD0: {0xD0, OpBNE, ModeREL, 2, 2, false},
+	0x10: {0x10, OpBPL, ModeREL, 2, 2, false},
+	0x50: {0x50, OpBVC, ModeREL, 2, 2, false},
+	0x70: {0x70, OpBVS, ModeREL, 2, 2, false},
+	0x24: {0x24, OpBIT, ModeZP, 2, 3, false},
+	0x2c: {0x2c, OpBIT, ModeABS, 3, 4, false},
+	0x00: {0x00, OpBRK, ModeIMP, 1, 7, false},
+	0x18: {0x18, OpCLC, ModeIMP, 1, 2, false},
+	0xd8: {0xd8, OpCLD, ModeIMP, 1, 2, false},
+	0x58: {0x58, OpCLI, ModeIMP, 1, 2, false},
+	0xb8: {0xb8, OpCLV, ModeIMP, 1, 2, false},
+	0xea: {0xea, OpNOP, ModeIMP, 1, 2, false},
+	0x48: {0x48, OpPHA, ModeIMP, 1, 3, false},
+	0x68: {0x68, OpPLA, ModeIMP, 1, 4, false},
+	0x08: {0x08, OpPHP, ModeIMP, 1, 3, false},
+	0x28: {0x28, OpPLP, ModeIMP, 1, 4, false},
+	0x40: {0x40, OpRTI, ModeIMP, 1, 6, false},
+	0x60: {0x60, OpRTS, ModeIMP, 1, 6, false},
+	0x38: {0x38, OpSEC, ModeIMP, 1, 2, false},
+	0xf8: {0xf8, OpSED, ModeIMP, 1, 2, false},
+	0x78: {0x78, OpSEI, ModeIMP, 1, 2, false},
+	0xaa: {0xaa, OpTAX, ModeIMP, 1, 2, false},
+	0x8a: {0x8a, OpTXA, ModeIMP, 1, 2, false},
+	0xa8: {0xa8, OpTAY, ModeIMP, 1, 2, false},
+	0x98: {0x98, OpTYA, ModeIMP, 1, 2, false},
+	0xba: {0xba, OpTSX, ModeIMP, 1, 2, false},
+	0x9a: {0x9a, OpTXS, ModeIMP, 1, 2, false},
+	0xc9: {0xc9, OpCMP, ModeIMM, 2, 2, false},
+	0xc5: {0xc5, OpCMP, ModeZP, 2, 3, false},
+	0xd5: {0xd5, OpCMP, ModeZPX, 2, 4, false},
+	0xcd: {0xcd, OpCMP, ModeABS, 3, 4, false},
+	0xdd: {0xdd, OpCMP, ModeABSX, 3, 4, true},
+	0xd9: {0xd9, OpCMP, ModeABSY, 3, 4, true},
+	0xc1: {0xc1, OpCMP, ModeINDX, 2, 6, false},
+	0xd1: {0xd1, OpCMP, ModeINDY, 2, 5, true},
+	0xe0: {0xe0, OpCPX, ModeIMM, 2, 2, false},
+	0xe4: {0xe4, OpCPX, ModeZP, 2, 3, false},
+	0xec: {0xec, OpCPX, ModeABS, 3, 4, false},
+	0xc0: {0xc0, OpCPY, ModeIMM, 2, 2, false},
+	0xc4: {0xc4, OpCPY, ModeZP, 2, 3, false},
+	0xcc: {0xcc, OpCPY, ModeABS, 3, 4, false},
+	0xc6: {0xc6, OpDEC, ModeZP, 2, 5, false},
+	0xd6: {0xd6, OpDEC, ModeZPX, 2, 6, false},
+	0xce: {0xce, OpDEC, ModeABS, 3, 6, false},
+	0xde: {0xde, OpDEC, ModeABSX, 3, 7, false},
+	0xca: {0xca, OpDEX, ModeIMP, 1, 2, false},
+	0x88: {0x88, OpDEY, ModeIMP, 1, 2, false},
+	0xe8: {0xe8, OpINX, ModeIMP, 1, 2, false},
+	0xc8: {0xc8, OpINY, ModeIMP, 1, 2, false},
+	0x49: {0x49, OpEOR, ModeIMM, 2, 2, false},
+	0x45: {0x45, OpEOR, ModeZP, 2, 3, false},
+	0x55: {0x55, OpEOR, ModeZPX, 2, 4, false},
+	0x4d: {0x4d, OpEOR, ModeABS, 3, 4, false},
+	0x5d: {0x5d, OpEOR, ModeABSX, 3, 4, true},
+	0x59: {0x59, OpEOR, ModeABSY, 3, 4, true},
+	0x41: {0x41, OpEOR, ModeINDX, 2, 6, false},
+	0x51: {0x51, OpEOR, ModeINDY, 2, 5, true},
+	0xe6: {0xe6, OpINC, ModeZP, 2, 5, false},
+	0xf6: {0xf6, OpINC, ModeZPX, 2, 6, false},
+	0xee: {0xee, OpINC, ModeABS, 3, 6, false},
+	0xfe: {0xfe, OpINC, ModeABSX, 3, 7, false},
+	0x4c: {0x4c, OpJMP, ModeABS, 3, 3, false},
+	0x6c: {0x6c, OpJMP, ModeIND, 3, 5, false},
+	0x20: {0x20, OpJSR, ModeABS, 3, 6, false},
+	0xa9: {0xa9, OpLDA, ModeIMM, 2, 2, false},
+	0xa5: {0xa5, OpLDA, ModeZP, 2, 3, false},
+	0xb5: {0xb5, OpLDA, ModeZPX, 2, 4, false},
+	0xad: {0xad, OpLDA, ModeABS, 3, 4, false},
+	0xbd: {0xbd, OpLDA, ModeABSX, 3, 4, true},
+	0xb9: {0xb9, OpLDA, ModeABSY, 3, 4, true},
+	0xa1: {0xa1, OpLDA, ModeINDX, 2, 6, false},
+	0xb1: {0xb1, OpLDA, ModeINDY, 2, 5, true},
+	0xa2: {0xa2, OpLDX, ModeIMM, 2, 2, false},
+	0xa6: {0xa6, OpLDX, ModeZP, 2, 3, false},
+	0xb6: {0xb6, OpLDX, ModeZPY, 2, 4, false},
+	0xae: {0xae, OpLDX, ModeABS, 3, 4, false},
+	0xbe: {0xbe, OpLDX, ModeABSY, 3, 4, true},
+	0xa0: {0xa0, OpLDY, ModeIMM, 2, 2, false},
+	0xa4: {0xa4, OpLDY, ModeZP, 2, 3, false},
+	0xb4: {0xb4, OpLDY, ModeZPX, 2, 4, false},
+	0xac: {0xac, OpLDY, ModeABS, 3, 4, false},
+	0xbc: {0xbc, OpLDY, ModeABSX, 3, 4, true},
+	0x4a: {0x4a, OpLSR, ModeACC, 1, 2, false},
+	0x46: {0x46, OpLSR, ModeZP, 2, 5, false},
+	0x56: {0x56, OpLSR, ModeZPX, 2, 6, false},
+	0x4e: {0x4e, OpLSR, ModeABS, 3, 6, false},
+	0x5e: {0x5e, OpLSR, ModeABSX, 3, 7, false},
+	0x09: {0x09, OpORA, ModeIMM, 2, 2, false},
+	0x05: {0x05, OpORA, ModeZP, 2, 3, false},
+	0x15: {0x15, OpORA, ModeZPX, 2, 4, false},
+	0x0d: {0x0d, OpORA, ModeABS, 3, 4, false},
+	0x1d: {0x1d, OpORA, ModeABSX, 3, 4, true},
+	0x19: {0x19, OpORA, ModeABSY, 3, 4, true},
+	0x01: {0x01, OpORA, ModeINDX, 2, 6, false},
+	0x11: {0x11, OpORA, ModeINDY, 2, 5, true},
+	0x2a: {0x2a, OpROL, ModeACC, 1, 2, false},
+	0x26: {0x26, OpROL, ModeZP, 2, 5, false},
+	0x36: {0x36, OpROL, ModeZPX, 2, 6, false},
+	0x2e: {0x2e, OpROL, ModeABS, 3, 6, false},
+	0x3e: {0x3e, OpROL, ModeABSX, 3, 7, false},
+	0x6a: {0x6a, OpROR, ModeACC, 1, 2, false},
+	0x66: {0x66, OpROR, ModeZP, 2, 5, false},
+	0x76: {0x76, OpROR, ModeZPX, 2, 6, false},
+	0x6e: {0x6e, OpROR, ModeABS, 3, 6, false},
+	0x7e: {0x7e, OpROR, ModeABSX, 3, 7, false},
+	0xe9: {0xe9, OpSBC, ModeIMM, 2, 2, false},
+	0xe5: {0xe5, OpSBC, ModeZP, 2, 3, false},
+	0xf5: {0xf5, OpSBC, ModeZPX, 2, 4, false},
+	0xed: {0xed, OpSBC, ModeABS, 3, 4, false},
+	0xfd: {0xfd, OpSBC, ModeABSX, 3, 4, true},
+	0xf9: {0xf9, OpSBC, ModeABSY, 3, 4, true},
+	0xe1: {0xe1, OpSBC, ModeINDX, 2, 6, false},
+	0xf1: {0xf1, OpSBC, ModeINDY, 2, 5, true},
+	0x85: {0x85, OpSTA, ModeZP, 2, 3, false},
+	0x95: {0x95, OpSTA, ModeZPX, 2, 4, false},
+	0x8d: {0x8d, OpSTA, ModeABS, 3, 4, false},
+	0x9d: {0x9d, OpSTA, ModeABSX, 3, 5, false},
+	0x99: {0x99, OpSTA, ModeABSY, 3, 5, false},
+	0x81: {0x81, OpSTA, ModeINDX, 2, 6, false},
+	0x91: {0x91, OpSTA, ModeINDY, 2, 6, false},
+	0x86: {0x86, OpSTX, ModeZP, 2, 3, false},
+	0x96: {0x96, OpSTX, ModeZPY, 2, 4, false},
+	0x8e: {0x8e, OpSTX, ModeABS, 3, 4, false},
+	0x84: {0x84, OpSTY, ModeZP, 2, 3, false},
+	0x94: {0x94, OpSTY, ModeZPX, 2, 4, false},
+	0x8c: {0x8c, OpSTY, ModeABS, 3, 4, false},
 }
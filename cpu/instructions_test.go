@@ -0,0 +1,73 @@
+package cpu
+
+import (
+	"github.com/edison-moreland/go6502/testingHelp"
+	"testing"
+)
+
+// Documented shifts and rotates, on the accumulator and on memory
+var shiftTests = []instructionTestData{
+	{"ROL rotates carry in", func(cpu *Go6502) {
+		cpu.A = 0x80
+		cpu.Stat.Carry = true
+	}, []byte{0x2a}, func(t *testing.T, cpu *Go6502) {
+		testingHelp.Equals(t, byte(0x01), cpu.A)
+		testingHelp.Equals(t, Status{Carry: true}, cpu.Stat)
+	}},
+	{"ROR rotates carry in", func(cpu *Go6502) {
+		cpu.A = 0x01
+		cpu.Stat.Carry = true
+	}, []byte{0x6a}, func(t *testing.T, cpu *Go6502) {
+		testingHelp.Equals(t, byte(0x80), cpu.A)
+		testingHelp.Equals(t, Status{Negative: true, Carry: true}, cpu.Stat)
+	}},
+	{"ROR absolute", func(cpu *Go6502) {
+		_ = cpu.Mem.WriteByte(0x2000, 0x02)
+	}, []byte{0x6e, 0x00, 0x20}, func(t *testing.T, cpu *Go6502) {
+		val, _ := cpu.Mem.ReadByte(0x2000)
+		testingHelp.Equals(t, byte(0x01), val)
+		testingHelp.Equals(t, Status{}, cpu.Stat)
+	}},
+	{"ROR absolute,X", func(cpu *Go6502) {
+		cpu.X = 0x05
+		_ = cpu.Mem.WriteByte(0x2005, 0x03)
+	}, []byte{0x7e, 0x00, 0x20}, func(t *testing.T, cpu *Go6502) {
+		val, _ := cpu.Mem.ReadByte(0x2005)
+		testingHelp.Equals(t, byte(0x01), val)
+		testingHelp.Equals(t, Status{Carry: true}, cpu.Stat)
+	}},
+	{"LSR accumulator", func(cpu *Go6502) {
+		cpu.A = 0x81
+	}, []byte{0x4a}, func(t *testing.T, cpu *Go6502) {
+		testingHelp.Equals(t, byte(0x40), cpu.A)
+		testingHelp.Equals(t, Status{Carry: true}, cpu.Stat)
+	}},
+	{"LSR zeropage", func(cpu *Go6502) {
+		_ = cpu.Mem.WriteByte(0x0010, 0x01)
+	}, []byte{0x46, 0x10}, func(t *testing.T, cpu *Go6502) {
+		val, _ := cpu.Mem.ReadByte(0x0010)
+		testingHelp.Equals(t, byte(0x00), val)
+		testingHelp.Equals(t, Status{Zero: true, Carry: true}, cpu.Stat)
+	}},
+}
+
+func TestShiftInstructions(t *testing.T) {
+	for _, testData := range shiftTests {
+		t.Run(testData.description, func(t *testing.T) {
+			cpu := new(Go6502)
+			testData.setup(cpu)
+
+			runInstruction(t, cpu, 0x1000, testData.program...)
+			testData.check(t, cpu)
+		})
+	}
+}
+
+func TestROR_Cycles(t *testing.T) {
+	// Absolute takes 6 cycles, absolute,X always takes 7
+	for opcode, cycles := range map[byte]uint64{0x6e: 6, 0x7e: 7} {
+		cpu := New()
+		runProgram(t, cpu, 0x1000, 1, opcode, 0x00, 0x20)
+		testingHelp.Equals(t, cycles, cpu.Cycles)
+	}
+}
@@ -0,0 +1,67 @@
+package cpu
+
+/*
+Interrupt lines
+---------------
+IRQ and NMI are active low lines shared by every device in the system, any
+device pulling a line low asserts it. Each device gets a source number (0-63)
+so the CPU can tell when the last one lets go.
+
+IRQ is level triggered. The CPU keeps taking interrupts for as long as the line
+is asserted and the I flag is clear, so a device has to release the line once
+it has been serviced.
+
+NMI is edge triggered. An interrupt happens once when the line goes from
+released to asserted, holding it asserted does nothing more.
+
+Interrupts are only taken between instructions. The I flag changes made by
+CLI, SEI, and PLP aren't seen until after the following instruction.
+*/
+
+func sourceMask(source uint) uint64 {
+	return 1 << (source & 63)
+}
+
+// AssertIRQ pulls the IRQ line low on behalf of source
+func (g6 *Go6502) AssertIRQ(source uint) {
+	g6.irqLines |= sourceMask(source)
+}
+
+// ReleaseIRQ lets go of the IRQ line for source
+func (g6 *Go6502) ReleaseIRQ(source uint) {
+	g6.irqLines &^= sourceMask(source)
+}
+
+// IRQAsserted reports if any source is holding the IRQ line low
+func (g6 *Go6502) IRQAsserted() bool {
+	return g6.irqLines != 0
+}
+
+// AssertNMI pulls the NMI line low on behalf of source, triggering an NMI if nothing else was
+func (g6 *Go6502) AssertNMI(source uint) {
+	if g6.nmiLines == 0 {
+		g6.nmiPending = true
+	}
+
+	g6.nmiLines |= sourceMask(source)
+}
+
+// ReleaseNMI lets go of the NMI line for source
+func (g6 *Go6502) ReleaseNMI(source uint) {
+	g6.nmiLines &^= sourceMask(source)
+}
+
+// NMIAsserted reports if any source is holding the NMI line low
+func (g6 *Go6502) NMIAsserted() bool {
+	return g6.nmiLines != 0
+}
+
+func (g6 *Go6502) irqMasked() bool {
+	switch g6.CurrentInstruction.Mnemonic {
+	case OpCLI, OpSEI, OpPLP:
+		// The I flag is polled before these instructions change it
+		return g6.interruptDisableBefore
+	}
+
+	return g6.Stat.InterruptDisable
+}
@@ -0,0 +1,137 @@
+package cpu
+
+import (
+	"github.com/edison-moreland/go6502/testingHelp"
+	"testing"
+)
+
+const irqHandler = 0x3000
+const nmiHandler = 0x4000
+
+func newInterruptCPU() *Go6502 {
+	cpu := New()
+	cpu.SP = 0xFF
+	_ = cpu.Mem.WriteWord(0xFFFE, irqHandler)
+	_ = cpu.Mem.WriteWord(0xFFFA, nmiHandler)
+
+	// Both handlers return straight away
+	_ = cpu.Mem.WriteByte(irqHandler, 0x40)
+	_ = cpu.Mem.WriteByte(nmiHandler, 0x40)
+	return cpu
+}
+
+func TestIRQ_RespectsInterruptDisable(t *testing.T) {
+	cpu := newInterruptCPU()
+	cpu.Stat.InterruptDisable = true
+	cpu.AssertIRQ(0)
+
+	runProgram(t, cpu, 0x1000, 1, 0xea) // NOP
+	testingHelp.Equals(t, uint16(0x1001), cpu.PC)
+
+	cpu.Stat.InterruptDisable = false
+	runProgram(t, cpu, 0x1000, 1, 0xea)
+	testingHelp.Equals(t, uint16(irqHandler), cpu.PC)
+	testingHelp.Equals(t, true, cpu.Stat.InterruptDisable)
+
+	// Hardware interrupts push the status without the B flag
+	status, _ := cpu.PopByteOffStack()
+	testingHelp.Equals(t, byte(0x20), status)
+}
+
+func TestIRQ_LevelTriggeredWiredOR(t *testing.T) {
+	cpu := newInterruptCPU()
+	cpu.AssertIRQ(1)
+	cpu.AssertIRQ(2)
+	cpu.ReleaseIRQ(1)
+	testingHelp.Equals(t, true, cpu.IRQAsserted())
+
+	// Still asserted after RTI, so the interrupt is taken again
+	runProgram(t, cpu, 0x1000, 2, 0xea)
+	testingHelp.Equals(t, uint16(irqHandler), cpu.PC)
+	testingHelp.Equals(t, byte(0xFC), cpu.SP)
+
+	cpu.ReleaseIRQ(2)
+	testingHelp.Equals(t, false, cpu.IRQAsserted())
+	runProgram(t, cpu, irqHandler, 1)
+	testingHelp.Equals(t, uint16(0x1001), cpu.PC)
+}
+
+func TestNMI_EdgeTriggered(t *testing.T) {
+	cpu := newInterruptCPU()
+	cpu.Stat.InterruptDisable = true
+	cpu.AssertNMI(0)
+	cpu.AssertNMI(1) // Line was already low, no new edge
+
+	runProgram(t, cpu, 0x1000, 1, 0xea, 0xea)
+	testingHelp.Equals(t, uint16(nmiHandler), cpu.PC)
+
+	// Holding the line low doesn't trigger again
+	runProgram(t, cpu, nmiHandler, 2)
+	testingHelp.Equals(t, uint16(0x1002), cpu.PC)
+
+	cpu.ReleaseNMI(0)
+	cpu.ReleaseNMI(1)
+	cpu.AssertNMI(0)
+	runProgram(t, cpu, 0x1002, 1, 0xea)
+	testingHelp.Equals(t, uint16(nmiHandler), cpu.PC)
+}
+
+func TestIRQ_CLILatency(t *testing.T) {
+	cpu := newInterruptCPU()
+	cpu.Stat.InterruptDisable = true
+	cpu.AssertIRQ(0)
+
+	// The instruction after CLI always runs before the interrupt
+	runProgram(t, cpu, 0x1000, 1, 0x58, 0xea) // CLI, NOP
+	testingHelp.Equals(t, uint16(0x1001), cpu.PC)
+
+	runProgram(t, cpu, 0x1001, 1)
+	testingHelp.Equals(t, uint16(irqHandler), cpu.PC)
+}
+
+func TestIRQ_SEILatency(t *testing.T) {
+	cpu := newInterruptCPU()
+	cpu.AssertIRQ(0)
+
+	// An IRQ can still sneak in straight after SEI
+	runProgram(t, cpu, 0x1000, 1, 0x78, 0xea) // SEI, NOP
+	testingHelp.Equals(t, uint16(irqHandler), cpu.PC)
+
+	// The pushed status has I set
+	status, _ := cpu.PopByteOffStack()
+	testingHelp.Equals(t, byte(0x24), status)
+}
+
+func TestWAI_WakesOnInterrupt(t *testing.T) {
+	cpu := newInterruptCPU()
+	cpu.SetVariant(WDC65C02)
+	cpu.Stat.InterruptDisable = true
+
+	// IRQ is masked, WAI just resumes once it's asserted
+	wake := &wakeAddon{after: 10}
+	wake.Register(cpu)
+	cpu.addons = []Addon{wake}
+	cpu.enableAddons = true
+
+	_ = cpu.Mem.WriteByte(0x1000, 0xcb) // WAI
+	_ = cpu.Mem.WriteByte(0x1001, 0xdb) // STP
+	err := cpu.StartEmulationAtAddress(0x1000)
+	testingHelp.Assert(t, err != nil, "STP should stop emulation")
+	testingHelp.Equals(t, uint16(0x1001), cpu.PC)
+	testingHelp.Equals(t, 0, wake.after)
+}
+
+// Asserts IRQ after a set number of cycles
+type wakeAddon struct {
+	BaseAddon
+	after int
+}
+
+func (wa *wakeAddon) AfterExecution() {
+	if wa.after > 0 {
+		wa.after--
+		if wa.after == 0 {
+			wa.G6.AssertIRQ(0)
+		}
+	}
+}
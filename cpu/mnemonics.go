@@ -0,0 +1,269 @@
+package cpu
+
+// Mnemonic identifies an instruction, independent of addressing mode
+type Mnemonic uint8
+
+const (
+	// OpUnknown marks an opcode with no instruction behind it
+	OpUnknown Mnemonic = iota
+
+	// Documented NMOS instructions
+	OpADC
+	OpAND
+	OpASL
+	OpBCC
+	OpBCS
+	OpBEQ
+	OpBIT
+	OpBMI
+	OpBNE
+	OpBPL
+	OpBRK
+	OpBVC
+	OpBVS
+	OpCLC
+	OpCLD
+	OpCLI
+	OpCLV
+	OpCMP
+	OpCPX
+	OpCPY
+	OpDEC
+	OpDEX
+	OpDEY
+	OpEOR
+	OpINC
+	OpINX
+	OpINY
+	OpJMP
+	OpJSR
+	OpLDA
+	OpLDX
+	OpLDY
+	OpLSR
+	OpNOP
+	OpORA
+	OpPHA
+	OpPHP
+	OpPLA
+	OpPLP
+	OpROL
+	OpROR
+	OpRTI
+	OpRTS
+	OpSBC
+	OpSEC
+	OpSED
+	OpSEI
+	OpSTA
+	OpSTX
+	OpSTY
+	OpTAX
+	OpTAY
+	OpTSX
+	OpTXA
+	OpTXS
+	OpTYA
+
+	// Undocumented NMOS instructions
+	OpALR
+	OpANC
+	OpARR
+	OpDCP
+	OpISC
+	OpJAM
+	OpLAS
+	OpLAX
+	OpLXA
+	OpRLA
+	OpRRA
+	OpSAX
+	OpSBX
+	OpSHA
+	OpSHX
+	OpSHY
+	OpSLO
+	OpSRE
+	OpTAS
+	OpUSBC
+	OpXAA
+
+	// 65C02 instructions
+	OpBRA
+	OpPHX
+	OpPHY
+	OpPLX
+	OpPLY
+	OpSTP
+	OpSTZ
+	OpTRB
+	OpTSB
+	OpWAI
+
+	// Rockwell bit instructions, consecutive so the bit number can be added on
+	OpRMB0
+	OpRMB1
+	OpRMB2
+	OpRMB3
+	OpRMB4
+	OpRMB5
+	OpRMB6
+	OpRMB7
+	OpSMB0
+	OpSMB1
+	OpSMB2
+	OpSMB3
+	OpSMB4
+	OpSMB5
+	OpSMB6
+	OpSMB7
+	OpBBR0
+	OpBBR1
+	OpBBR2
+	OpBBR3
+	OpBBR4
+	OpBBR5
+	OpBBR6
+	OpBBR7
+	OpBBS0
+	OpBBS1
+	OpBBS2
+	OpBBS3
+	OpBBS4
+	OpBBS5
+	OpBBS6
+	OpBBS7
+
+	mnemonicCount
+)
+
+var mnemonicNames = [mnemonicCount]string{
+	OpUnknown: "???",
+	OpADC:     "ADC",
+	OpAND:     "AND",
+	OpASL:     "ASL",
+	OpBCC:     "BCC",
+	OpBCS:     "BCS",
+	OpBEQ:     "BEQ",
+	OpBIT:     "BIT",
+	OpBMI:     "BMI",
+	OpBNE:     "BNE",
+	OpBPL:     "BPL",
+	OpBRK:     "BRK",
+	OpBVC:     "BVC",
+	OpBVS:     "BVS",
+	OpCLC:     "CLC",
+	OpCLD:     "CLD",
+	OpCLI:     "CLI",
+	OpCLV:     "CLV",
+	OpCMP:     "CMP",
+	OpCPX:     "CPX",
+	OpCPY:     "CPY",
+	OpDEC:     "DEC",
+	OpDEX:     "DEX",
+	OpDEY:     "DEY",
+	OpEOR:     "EOR",
+	OpINC:     "INC",
+	OpINX:     "INX",
+	OpINY:     "INY",
+	OpJMP:     "JMP",
+	OpJSR:     "JSR",
+	OpLDA:     "LDA",
+	OpLDX:     "LDX",
+	OpLDY:     "LDY",
+	OpLSR:     "LSR",
+	OpNOP:     "NOP",
+	OpORA:     "ORA",
+	OpPHA:     "PHA",
+	OpPHP:     "PHP",
+	OpPLA:     "PLA",
+	OpPLP:     "PLP",
+	OpROL:     "ROL",
+	OpROR:     "ROR",
+	OpRTI:     "RTI",
+	OpRTS:     "RTS",
+	OpSBC:     "SBC",
+	OpSEC:     "SEC",
+	OpSED:     "SED",
+	OpSEI:     "SEI",
+	OpSTA:     "STA",
+	OpSTX:     "STX",
+	OpSTY:     "STY",
+	OpTAX:     "TAX",
+	OpTAY:     "TAY",
+	OpTSX:     "TSX",
+	OpTXA:     "TXA",
+	OpTXS:     "TXS",
+	OpTYA:     "TYA",
+	OpALR:     "ALR",
+	OpANC:     "ANC",
+	OpARR:     "ARR",
+	OpDCP:     "DCP",
+	OpISC:     "ISC",
+	OpJAM:     "JAM",
+	OpLAS:     "LAS",
+	OpLAX:     "LAX",
+	OpLXA:     "LXA",
+	OpRLA:     "RLA",
+	OpRRA:     "RRA",
+	OpSAX:     "SAX",
+	OpSBX:     "SBX",
+	OpSHA:     "SHA",
+	OpSHX:     "SHX",
+	OpSHY:     "SHY",
+	OpSLO:     "SLO",
+	OpSRE:     "SRE",
+	OpTAS:     "TAS",
+	OpUSBC:    "USBC",
+	OpXAA:     "XAA",
+	OpBRA:     "BRA",
+	OpPHX:     "PHX",
+	OpPHY:     "PHY",
+	OpPLX:     "PLX",
+	OpPLY:     "PLY",
+	OpSTP:     "STP",
+	OpSTZ:     "STZ",
+	OpTRB:     "TRB",
+	OpTSB:     "TSB",
+	OpWAI:     "WAI",
+	OpRMB0:    "RMB0",
+	OpRMB1:    "RMB1",
+	OpRMB2:    "RMB2",
+	OpRMB3:    "RMB3",
+	OpRMB4:    "RMB4",
+	OpRMB5:    "RMB5",
+	OpRMB6:    "RMB6",
+	OpRMB7:    "RMB7",
+	OpSMB0:    "SMB0",
+	OpSMB1:    "SMB1",
+	OpSMB2:    "SMB2",
+	OpSMB3:    "SMB3",
+	OpSMB4:    "SMB4",
+	OpSMB5:    "SMB5",
+	OpSMB6:    "SMB6",
+	OpSMB7:    "SMB7",
+	OpBBR0:    "BBR0",
+	OpBBR1:    "BBR1",
+	OpBBR2:    "BBR2",
+	OpBBR3:    "BBR3",
+	OpBBR4:    "BBR4",
+	OpBBR5:    "BBR5",
+	OpBBR6:    "BBR6",
+	OpBBR7:    "BBR7",
+	OpBBS0:    "BBS0",
+	OpBBS1:    "BBS1",
+	OpBBS2:    "BBS2",
+	OpBBS3:    "BBS3",
+	OpBBS4:    "BBS4",
+	OpBBS5:    "BBS5",
+	OpBBS6:    "BBS6",
+	OpBBS7:    "BBS7",
+}
+
+func (m Mnemonic) String() string {
+	if m >= mnemonicCount {
+		return mnemonicNames[OpUnknown]
+	}
+
+	return mnemonicNames[m]
+}
@@ -0,0 +1,38 @@
+package cpu
+
+import "github.com/edison-moreland/go6502/memory"
+
+// Option configures a Go6502 created by New
+type Option func(g6 *Go6502)
+
+// New creates a CPU, by default an NMOS 6502 with memory cleared to zero.
+// Call Reset once the ROMs are loaded to start it up like a real one
+func New(options ...Option) *Go6502 {
+	g6 := new(Go6502)
+	for _, option := range options {
+		option(g6)
+	}
+
+	return g6
+}
+
+// WithVariant selects which member of the 6502 family to emulate
+func WithVariant(variant Variant) Option {
+	return func(g6 *Go6502) {
+		g6.SetVariant(variant)
+	}
+}
+
+// WithHistory records the last steps so they can be undone with StepBack and RewindTo
+func WithHistory(steps int) Option {
+	return func(g6 *Go6502) {
+		g6.EnableHistory(steps)
+	}
+}
+
+// WithBus makes the CPU read and write through bus instead of Mem
+func WithBus(bus memory.Bus) Option {
+	return func(g6 *Go6502) {
+		g6.Bus = bus
+	}
+}
@@ -0,0 +1,114 @@
+package cpu
+
+/*
+6510 I/O port
+-------------
+The 6510 has an I/O port built in, mapped over the first two bytes of memory:
+$0000: Data direction register, a 1 bit makes that line an output
+$0001: Data register
+
+Reading $0001 returns the level on each line. Outputs read back what was
+written, inputs read whatever is driving them. On the C64 lines 0-2 select
+which ROMs are banked in and have pull-up resistors, so they read as 1 while
+they are inputs.
+
+A line with nothing driving it floats. It holds on to the last level it was
+driven to for a while before discharging to 0, code that detects emulators
+relies on this for bits 6 and 7.
+
+Writes to $0000/$0001 still reach the RAM underneath, reads never do.
+*/
+
+// Address of the 6510 data direction and data registers
+const IOPortDataDirection = 0x0000
+const IOPortData = 0x0001
+
+// Roughly how long a floating line holds a 1 on a real 6510, in cycles
+const DefaultFalloffCycles = 350000
+
+type IOPort struct {
+	// DataDirection is $0000, a 1 bit makes that line an output
+	DataDirection byte
+	// Data is $0001, the level driven on each output line
+	Data byte
+
+	// PullUps are lines with a pull-up resistor, they read as 1 when they are inputs
+	PullUps byte
+	// ExternalMask are lines driven by another device, ExternalInputs is the level they are driven to
+	ExternalMask, ExternalInputs byte
+	// FalloffCycles is how long a floating line holds a 1 before discharging
+	FalloffCycles uint64
+
+	// OnChange is called with the new line levels whenever a write changes them
+	OnChange func(lines byte)
+
+	// Floating lines that are still charged, and the cycle they started floating on
+	charged       byte
+	floatingSince [8]uint64
+}
+
+func NewIOPort() *IOPort {
+	return &IOPort{FalloffCycles: DefaultFalloffCycles}
+}
+
+func (p *IOPort) Lines(cycle uint64) (lines byte) {
+	// Level on every line of the port at the given cycle
+	for bit := uint(0); bit < 8; bit++ {
+		mask := byte(1) << bit
+
+		var level bool
+		switch {
+		case p.DataDirection&mask != 0:
+			level = p.Data&mask != 0
+		case p.ExternalMask&mask != 0:
+			level = p.ExternalInputs&mask != 0
+		case p.PullUps&mask != 0:
+			level = true
+		default:
+			// Floating, charge leaks away eventually. If Cycles was moved back
+			// past when the line started floating it has only just started
+			floating := uint64(0)
+			if cycle > p.floatingSince[bit] {
+				floating = cycle - p.floatingSince[bit]
+			}
+			level = p.charged&mask != 0 && floating < p.FalloffCycles
+		}
+
+		if level {
+			lines |= mask
+		}
+	}
+
+	return lines
+}
+
+func (p *IOPort) Read(address uint16, cycle uint64) byte {
+	if address == IOPortDataDirection {
+		return p.DataDirection
+	}
+
+	return p.Lines(cycle)
+}
+
+func (p *IOPort) Write(address uint16, val byte, cycle uint64) {
+	oldLines := p.Lines(cycle)
+
+	if address == IOPortDataDirection {
+		// Lines that stop being outputs start floating at their current level
+		for bit := uint(0); bit < 8; bit++ {
+			mask := byte(1) << bit
+			if p.DataDirection&mask != 0 && val&mask == 0 {
+				p.charged = p.charged&^mask | oldLines&mask
+				p.floatingSince[bit] = cycle
+			}
+		}
+
+		p.DataDirection = val
+	} else {
+		p.Data = val
+	}
+
+	if newLines := p.Lines(cycle); newLines != oldLines && p.OnChange != nil {
+		p.OnChange(newLines)
+	}
+}
@@ -0,0 +1,94 @@
+package cpu
+
+import (
+	"github.com/edison-moreland/go6502/testingHelp"
+	"testing"
+)
+
+func TestIOPort_ReadWrite(t *testing.T) {
+	port := NewIOPort()
+	port.PullUps = 0x07
+
+	// Everything is an input at power on, only pulled up lines read high
+	testingHelp.Equals(t, byte(0x07), port.Read(IOPortData, 0))
+
+	port.Write(IOPortDataDirection, 0x2F, 0)
+	port.Write(IOPortData, 0x25, 0)
+	testingHelp.Equals(t, byte(0x2F), port.Read(IOPortDataDirection, 0))
+	testingHelp.Equals(t, byte(0x25), port.Read(IOPortData, 0))
+}
+
+func TestIOPort_ExternalInputs(t *testing.T) {
+	port := NewIOPort()
+	port.PullUps = 0x10
+	port.ExternalMask = 0x10
+	port.ExternalInputs = 0x00
+
+	// A pressed cassette button pulls the line low
+	testingHelp.Equals(t, byte(0x00), port.Read(IOPortData, 0))
+}
+
+func TestIOPort_FloatingLinesFallOff(t *testing.T) {
+	port := NewIOPort()
+	port.FalloffCycles = 1000
+
+	// Drive bit 7 high then turn it into an input
+	port.Write(IOPortDataDirection, 0x80, 0)
+	port.Write(IOPortData, 0x80, 0)
+	port.Write(IOPortDataDirection, 0x00, 100)
+
+	testingHelp.Equals(t, byte(0x80), port.Read(IOPortData, 500))
+	testingHelp.Equals(t, byte(0x00), port.Read(IOPortData, 1100))
+}
+
+func TestIOPort_FloatingCyclesMovedBack(t *testing.T) {
+	port := NewIOPort()
+	port.FalloffCycles = 1000
+
+	port.Write(IOPortDataDirection, 0x80, 0)
+	port.Write(IOPortData, 0x80, 0)
+	port.Write(IOPortDataDirection, 0x00, 5000)
+
+	// Cycles reset to before the line started floating, it's still charged
+	testingHelp.Equals(t, byte(0x80), port.Read(IOPortData, 10))
+}
+
+func TestIOPort_OnChange(t *testing.T) {
+	port := NewIOPort()
+	port.PullUps = 0x07
+
+	var changes []byte
+	port.OnChange = func(lines byte) {
+		changes = append(changes, lines)
+	}
+
+	port.Write(IOPortData, 0x06, 0)          // Lines are still inputs, nothing changes
+	port.Write(IOPortDataDirection, 0x07, 0) // Bit 0 is now driven low
+	testingHelp.Equals(t, []byte{0x06}, changes)
+}
+
+func TestGo6502_IOPort(t *testing.T) {
+	cpu := New(WithVariant(MOS6510))
+	cpu.Port().PullUps = 0x07
+
+	// STA $01 reaches the port and the RAM underneath
+	cpu.A = 0x2F
+	runInstruction(t, cpu, 0x1000, 0x85, 0x00)
+	cpu.A = 0x05
+	runInstruction(t, cpu, 0x1000, 0x85, 0x01)
+
+	ram, _ := cpu.Mem.ReadByte(0x0001)
+	testingHelp.Equals(t, byte(0x05), ram)
+
+	// LDA $01 reads the port, inputs are pulled up
+	_ = cpu.Mem.WriteByte(0x0001, 0xFF)
+	runInstruction(t, cpu, 0x1000, 0xa5, 0x01)
+	testingHelp.Equals(t, byte(0x05), cpu.A)
+
+	cpu.Port().DataDirection = 0x00
+	runInstruction(t, cpu, 0x1000, 0xa5, 0x01)
+	testingHelp.Equals(t, byte(0x07), cpu.A)
+
+	// Other variants don't have a port
+	testingHelp.Assert(t, New().Port() == nil, "NMOS 6502 should not have an I/O port")
+}
@@ -0,0 +1,76 @@
+package cpu
+
+import (
+	"github.com/edison-moreland/go6502/memory"
+	"github.com/pkg/errors"
+	"math/rand"
+)
+
+/*
+Power on and reset
+------------------
+Registers and RAM are undefined at power on. Reset doesn't clear anything
+either, it runs the interrupt sequence with the stack writes turned into reads:
+
+SP is decremented 3 times, nothing is written
+I is set, and D is cleared on the 65C02
+PC is loaded from the RST vector at $FFFC
+It takes 7 cycles
+
+From SP=0 that leaves SP at $FD, which is where most code expects it.
+
+Real RAM powers on in a pattern that depends on the chips, software that reads
+memory before writing it can behave differently depending on what it finds.
+*/
+
+// RAMPattern fills memory with what it holds at power on
+type RAMPattern func(mem *memory.Memory)
+
+// RAMZeros fills memory with $00, the default
+func RAMZeros(mem *memory.Memory) {
+	mem.Mem = [len(mem.Mem)]byte{}
+}
+
+// RAMOnes fills memory with $FF
+func RAMOnes(mem *memory.Memory) {
+	for i := range mem.Mem {
+		mem.Mem[i] = 0xFF
+	}
+}
+
+// RAMAlternating fills memory with 64 bytes of $00 then 64 bytes of $FF, like the RAM in most C64s
+func RAMAlternating(mem *memory.Memory) {
+	for i := range mem.Mem {
+		if i&0x40 == 0 {
+			mem.Mem[i] = 0x00
+		} else {
+			mem.Mem[i] = 0xFF
+		}
+	}
+}
+
+// RAMRandom fills memory with random bytes, the same seed always gives the same memory
+func RAMRandom(seed int64) RAMPattern {
+	return func(mem *memory.Memory) {
+		random := rand.New(rand.NewSource(seed))
+		random.Read(mem.Mem[:])
+	}
+}
+
+// WithRAMPattern fills memory with pattern at power on
+func WithRAMPattern(pattern RAMPattern) Option {
+	return func(g6 *Go6502) {
+		pattern(&g6.Mem)
+	}
+}
+
+func (g6 *Go6502) Reset() (err error) {
+	// Runs the reset sequence, run with Step/RunFor/RunUntil/Run afterwards
+	g6.interruptOccurred = true
+	g6.currentInterruptType = RST
+	if err = g6.HandleInterrupts(); err != nil {
+		return errors.Wrap(err, "Error handling RESET")
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package cpu
+
+import (
+	"github.com/edison-moreland/go6502/testingHelp"
+	"testing"
+)
+
+func TestGo6502_Reset(t *testing.T) {
+	cpu := &Go6502{}
+	_ = cpu.Mem.WriteWord(0xFFFC, 0xE000)
+	cpu.Stat.Decimal = true
+
+	testingHelp.NotNil(t, cpu.Reset())
+	testingHelp.Equals(t, uint16(0xE000), cpu.PC)
+	testingHelp.Equals(t, byte(0xFD), cpu.SP)
+	testingHelp.Equals(t, true, cpu.Stat.InterruptDisable)
+	testingHelp.Equals(t, uint64(interruptCycles), cpu.Cycles)
+
+	// NMOS leaves D alone
+	testingHelp.Equals(t, true, cpu.Stat.Decimal)
+
+	// Nothing is pushed
+	testingHelp.Equals(t, [3]byte{}, [3]byte{cpu.Mem.Mem[0x01FD], cpu.Mem.Mem[0x01FE], cpu.Mem.Mem[0x01FF]})
+}
+
+func TestGo6502_ResetCMOS(t *testing.T) {
+	cpu := New(WithVariant(WDC65C02))
+	cpu.Stat.Decimal = true
+
+	testingHelp.NotNil(t, cpu.Reset())
+	testingHelp.Equals(t, false, cpu.Stat.Decimal)
+}
+
+func TestGo6502_ResetAfterHalt(t *testing.T) {
+	cpu := New()
+	_ = cpu.Mem.WriteWord(0xFFFC, 0x1000)
+	_ = cpu.Mem.WriteByte(0x1000, 0x02) // JAM
+
+	testingHelp.NotNil(t, cpu.Reset())
+	_, err := cpu.Step()
+	testingHelp.Assert(t, err != nil, "expected JAM to halt the CPU")
+
+	// Reset again, SP keeps going down
+	_ = cpu.Mem.WriteByte(0x1000, 0xea) // NOP
+	testingHelp.NotNil(t, cpu.Reset())
+	testingHelp.Equals(t, byte(0xFA), cpu.SP)
+
+	_, err = cpu.Step()
+	testingHelp.NotNil(t, err)
+	testingHelp.Equals(t, uint16(0x1001), cpu.PC)
+}
+
+func TestRAMPatterns(t *testing.T) {
+	cpu := New(WithRAMPattern(RAMOnes))
+	testingHelp.Equals(t, byte(0xFF), cpu.Mem.Mem[0x0000])
+	testingHelp.Equals(t, byte(0xFF), cpu.Mem.Mem[0xFFFF])
+
+	cpu = New(WithRAMPattern(RAMAlternating))
+	testingHelp.Equals(t, byte(0x00), cpu.Mem.Mem[0x003F])
+	testingHelp.Equals(t, byte(0xFF), cpu.Mem.Mem[0x0040])
+	testingHelp.Equals(t, byte(0xFF), cpu.Mem.Mem[0x007F])
+	testingHelp.Equals(t, byte(0x00), cpu.Mem.Mem[0x0080])
+
+	cpu = New(WithRAMPattern(RAMOnes), WithRAMPattern(RAMZeros))
+	testingHelp.Equals(t, byte(0x00), cpu.Mem.Mem[0x1234])
+}
+
+func TestRAMRandom(t *testing.T) {
+	first := New(WithRAMPattern(RAMRandom(6502)))
+	second := New(WithRAMPattern(RAMRandom(6502)))
+	other := New(WithRAMPattern(RAMRandom(6510)))
+
+	testingHelp.Equals(t, first.Mem, second.Mem)
+	testingHelp.Assert(t, first.Mem != other.Mem, "expected different seeds to give different memory")
+}
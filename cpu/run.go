@@ -0,0 +1,109 @@
+package cpu
+
+import (
+	"context"
+	"github.com/pkg/errors"
+)
+
+/*
+Execution control
+-----------------
+StartEmulation runs until an addon calls StopEmulation. Everything here runs
+the same loop, but stops on its own:
+
+Step:     Executes a single instruction and reports what happened
+RunFor:   Runs for a number of instructions or cycles
+RunUntil: Runs until a predicate returns true
+Run:      Runs until the context is cancelled
+
+All of them still stop early on StopEmulation, a JAM/STP or an error. They
+carry on from the current PC, a reset has to be triggered separately.
+*/
+
+// StepResult describes what a single step executed
+type StepResult struct {
+	// PC is where the instruction was fetched from
+	PC uint16
+	// Instruction that was executed, zero if the CPU was waiting
+	Instruction Instruction
+	// Cycles taken by the instruction plus any interrupt entered afterwards
+	Cycles uint64
+	// Interrupt entered after the instruction, empty if there wasn't one
+	Interrupt string
+	// Waiting is set if the CPU was sleeping after a WAI instead of executing
+	Waiting bool
+}
+
+// RunUnit is what RunFor counts in
+type RunUnit byte
+
+const (
+	// UnitInstructions counts executed instructions
+	UnitInstructions RunUnit = iota
+	// UnitCycles counts clock cycles, the last instruction may overshoot
+	UnitCycles
+)
+
+// How many steps Run takes between checking the context
+const contextCheckInterval = 1024
+
+func (g6 *Go6502) Step() (result StepResult, err error) {
+	// Executes exactly one instruction, or one cycle of waiting after WAI
+	if err = g6.startRunning(); err != nil {
+		return result, err
+	}
+	defer g6.stopRunning()
+	defer g6.panicRecovery(&err)
+	g6.prepareRun()
+
+	return g6.step()
+}
+
+func (g6 *Go6502) Waiting() bool {
+	// True while WAI has the CPU sleeping until an interrupt
+	return g6.waiting
+}
+
+func (g6 *Go6502) RunFor(n uint64, unit RunUnit) (err error) {
+	if n == 0 {
+		return nil
+	}
+
+	switch unit {
+	case UnitInstructions:
+		target := g6.Instructions + n
+		return g6.run(func(g6 *Go6502) bool {
+			return g6.Instructions >= target
+		})
+	case UnitCycles:
+		target := g6.Cycles + n
+		return g6.run(func(g6 *Go6502) bool {
+			return g6.Cycles >= target
+		})
+	}
+
+	return errors.Errorf("Unknown run unit %#v", unit)
+}
+
+func (g6 *Go6502) RunUntil(predicate func(g6 *Go6502) bool) (err error) {
+	// Predicate is checked after every instruction
+	return g6.run(predicate)
+}
+
+func (g6 *Go6502) Run(ctx context.Context) (err error) {
+	// Runs until ctx is done, returning the context's error
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+
+	steps := 0
+	err = g6.run(func(g6 *Go6502) bool {
+		steps++
+		return steps%contextCheckInterval == 0 && ctx.Err() != nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctx.Err()
+}
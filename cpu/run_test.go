@@ -0,0 +1,119 @@
+package cpu
+
+import (
+	"context"
+	"github.com/edison-moreland/go6502/testingHelp"
+	"testing"
+	"time"
+)
+
+// Counts up in X forever
+var loopProgram = []byte{
+	0xe8,             // $1000 INX
+	0x4c, 0x00, 0x10, // $1001 JMP $1000
+}
+
+func newLoopCPU() *Go6502 {
+	cpu := New()
+	for i, programByte := range loopProgram {
+		_ = cpu.Mem.WriteByte(0x1000+uint16(i), programByte)
+	}
+	cpu.PC = 0x1000
+	return cpu
+}
+
+func TestGo6502_Step(t *testing.T) {
+	cpu := newLoopCPU()
+
+	result, err := cpu.Step()
+	testingHelp.NotNil(t, err)
+	testingHelp.Equals(t, uint16(0x1000), result.PC)
+	testingHelp.Equals(t, OpINX, result.Instruction.Mnemonic)
+	testingHelp.Equals(t, uint64(2), result.Cycles)
+	testingHelp.Equals(t, "", result.Interrupt)
+	testingHelp.Equals(t, uint16(0x1001), cpu.PC)
+
+	result, err = cpu.Step()
+	testingHelp.NotNil(t, err)
+	testingHelp.Equals(t, OpJMP, result.Instruction.Mnemonic)
+	testingHelp.Equals(t, uint16(0x1000), cpu.PC)
+	testingHelp.Equals(t, uint64(2), cpu.Instructions)
+}
+
+func TestGo6502_StepInterrupt(t *testing.T) {
+	cpu := newLoopCPU()
+	_ = cpu.Mem.WriteWord(0xFFFA, 0x2000)
+	cpu.AssertNMI(0)
+
+	result, err := cpu.Step()
+	testingHelp.NotNil(t, err)
+	testingHelp.Equals(t, NMI, result.Interrupt)
+	testingHelp.Equals(t, uint64(2+interruptCycles), result.Cycles)
+	testingHelp.Equals(t, uint16(0x2000), cpu.PC)
+}
+
+func TestGo6502_StepHalted(t *testing.T) {
+	cpu := New()
+	_ = cpu.Mem.WriteByte(0x1000, 0x02) // JAM
+	cpu.PC = 0x1000
+
+	_, err := cpu.Step()
+	testingHelp.Assert(t, err != nil, "JAM should return an error")
+}
+
+func TestGo6502_RunForInstructions(t *testing.T) {
+	cpu := newLoopCPU()
+
+	testingHelp.NotNil(t, cpu.RunFor(10, UnitInstructions))
+	testingHelp.Equals(t, uint64(10), cpu.Instructions)
+	testingHelp.Equals(t, byte(5), cpu.X)
+
+	// Carries on from where it stopped
+	testingHelp.NotNil(t, cpu.RunFor(10, UnitInstructions))
+	testingHelp.Equals(t, byte(10), cpu.X)
+}
+
+func TestGo6502_RunForCycles(t *testing.T) {
+	cpu := newLoopCPU()
+
+	// INX and JMP take 2 + 3 cycles, the run stops on the first instruction boundary past the target
+	testingHelp.NotNil(t, cpu.RunFor(11, UnitCycles))
+	testingHelp.Equals(t, uint64(12), cpu.Cycles)
+	testingHelp.Equals(t, byte(3), cpu.X)
+}
+
+func TestGo6502_RunUntil(t *testing.T) {
+	cpu := newLoopCPU()
+
+	err := cpu.RunUntil(func(g6 *Go6502) bool {
+		return g6.X == 0x42
+	})
+	testingHelp.NotNil(t, err)
+	testingHelp.Equals(t, byte(0x42), cpu.X)
+	testingHelp.Equals(t, uint16(0x1001), cpu.PC)
+}
+
+func TestGo6502_Run(t *testing.T) {
+	cpu := newLoopCPU()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := cpu.Run(ctx)
+	testingHelp.Equals(t, context.DeadlineExceeded, err)
+	testingHelp.Assert(t, cpu.Instructions > 0, "expected some instructions to run")
+}
+
+func TestGo6502_RunStopEmulation(t *testing.T) {
+	cpu := newLoopCPU()
+
+	// Addons can still stop a run early
+	err := cpu.RunUntil(func(g6 *Go6502) bool {
+		if g6.X == 3 {
+			g6.StopEmulation()
+		}
+		return false
+	})
+	testingHelp.NotNil(t, err)
+	testingHelp.Equals(t, byte(3), cpu.X)
+}
@@ -0,0 +1,124 @@
+package cpu
+
+import "container/heap"
+
+/*
+Event scheduler
+---------------
+Devices schedule a callback for a cycle instead of checking the cycle count
+after every instruction. Events run at the first instruction boundary at or
+after their cycle, in cycle order, and before interrupts are handled so an
+event can assert IRQ or NMI and have it taken straight away. While the CPU is
+waiting after WAI events run on the exact cycle.
+
+The callback is given the cycle the event was scheduled for rather than the
+current cycle, so a periodic device can schedule its next event relative to it
+and never drift:
+
+	func (t *timer) tick(cycle uint64) {
+		t.g6.Reschedule(t.event, cycle+t.period)
+	}
+
+Events belong to the emulation goroutine, use Inspect to schedule from anywhere
+else. Events aren't saved in save states, a StatefulAddon should reschedule its
+events when its state is loaded.
+*/
+
+// Event is a callback scheduled for a cycle
+type Event struct {
+	cycle    uint64
+	callback func(cycle uint64)
+
+	// Events on the same cycle run in the order they were scheduled
+	sequence uint64
+	// Position in the queue plus one, so a zero Event isn't scheduled
+	position int
+}
+
+func (e *Event) Cycle() uint64 {
+	return e.cycle
+}
+
+func (e *Event) Scheduled() bool {
+	return e.position != 0
+}
+
+// eventQueue is a min heap of events ordered by cycle
+type eventQueue struct {
+	events   []*Event
+	sequence uint64
+}
+
+func (q *eventQueue) Len() int { return len(q.events) }
+
+func (q *eventQueue) Less(i, j int) bool {
+	if q.events[i].cycle != q.events[j].cycle {
+		return q.events[i].cycle < q.events[j].cycle
+	}
+	return q.events[i].sequence < q.events[j].sequence
+}
+
+func (q *eventQueue) Swap(i, j int) {
+	q.events[i], q.events[j] = q.events[j], q.events[i]
+	q.events[i].position = i + 1
+	q.events[j].position = j + 1
+}
+
+func (q *eventQueue) Push(x interface{}) {
+	event := x.(*Event)
+	event.position = len(q.events) + 1
+	q.events = append(q.events, event)
+}
+
+func (q *eventQueue) Pop() interface{} {
+	last := len(q.events) - 1
+	event := q.events[last]
+	q.events[last] = nil
+	q.events = q.events[:last]
+
+	event.position = 0
+	return event
+}
+
+func (q *eventQueue) due(cycle uint64) bool {
+	return len(q.events) != 0 && q.events[0].cycle <= cycle
+}
+
+func (g6 *Go6502) Schedule(cycle uint64, callback func(cycle uint64)) *Event {
+	// Runs callback once Cycles reaches cycle, a cycle in the past runs at the next boundary
+	event := &Event{callback: callback}
+	g6.Reschedule(event, cycle)
+	return event
+}
+
+func (g6 *Go6502) ScheduleIn(cycles uint64, callback func(cycle uint64)) *Event {
+	// Runs callback in cycles cycles from now
+	return g6.Schedule(g6.Cycles+cycles, callback)
+}
+
+func (g6 *Go6502) Reschedule(event *Event, cycle uint64) {
+	// Moves an event to a new cycle, scheduling it again if it already ran or was cancelled
+	event.cycle = cycle
+	event.sequence = g6.events.sequence
+	g6.events.sequence++
+
+	if event.Scheduled() {
+		heap.Fix(&g6.events, event.position-1)
+	} else {
+		heap.Push(&g6.events, event)
+	}
+}
+
+func (g6 *Go6502) Cancel(event *Event) {
+	if event.Scheduled() {
+		heap.Remove(&g6.events, event.position-1)
+	}
+}
+
+func (g6 *Go6502) runEvents() {
+	// Callbacks can schedule more events, including ones that are already due
+	for g6.events.due(g6.Cycles) {
+		event := heap.Pop(&g6.events).(*Event)
+		event.callback(event.cycle)
+	}
+}
@@ -0,0 +1,121 @@
+package cpu
+
+import (
+	"github.com/edison-moreland/go6502/testingHelp"
+	"testing"
+)
+
+func TestScheduler_RunsInOrder(t *testing.T) {
+	cpu := newLoopCPU()
+
+	var fired []uint64
+	record := func(cycle uint64) {
+		fired = append(fired, cycle)
+	}
+	cpu.Schedule(20, record)
+	cpu.Schedule(4, record)
+	cpu.Schedule(11, record)
+
+	// INX and JMP take 2 + 3 cycles, events run on the first boundary after their cycle
+	var ranAt []uint64
+	for cpu.Cycles < 25 {
+		before := len(fired)
+		_, err := cpu.Step()
+		testingHelp.NotNil(t, err)
+		if len(fired) > before {
+			ranAt = append(ranAt, cpu.Cycles)
+		}
+	}
+
+	testingHelp.Equals(t, []uint64{4, 11, 20}, fired)
+	testingHelp.Equals(t, []uint64{5, 12, 20}, ranAt)
+}
+
+func TestScheduler_SameCycleFIFO(t *testing.T) {
+	cpu := newLoopCPU()
+
+	var order []string
+	cpu.Schedule(2, func(cycle uint64) { order = append(order, "first") })
+	cpu.Schedule(2, func(cycle uint64) { order = append(order, "second") })
+	cpu.Schedule(2, func(cycle uint64) { order = append(order, "third") })
+
+	_, err := cpu.Step()
+	testingHelp.NotNil(t, err)
+	testingHelp.Equals(t, []string{"first", "second", "third"}, order)
+}
+
+func TestScheduler_CancelReschedule(t *testing.T) {
+	cpu := newLoopCPU()
+
+	fired := 0
+	cancelled := cpu.Schedule(5, func(cycle uint64) { fired++ })
+	moved := cpu.Schedule(5, func(cycle uint64) { fired += 10 })
+
+	cpu.Cancel(cancelled)
+	testingHelp.Equals(t, false, cancelled.Scheduled())
+	cpu.Reschedule(moved, 50)
+	testingHelp.Equals(t, uint64(50), moved.Cycle())
+
+	testingHelp.NotNil(t, cpu.RunFor(40, UnitCycles))
+	testingHelp.Equals(t, 0, fired)
+
+	testingHelp.NotNil(t, cpu.RunFor(20, UnitCycles))
+	testingHelp.Equals(t, 10, fired)
+	testingHelp.Equals(t, false, moved.Scheduled())
+}
+
+func TestScheduler_ZeroEvent(t *testing.T) {
+	cpu := newLoopCPU()
+
+	fired := 0
+	scheduled := cpu.Schedule(5, func(cycle uint64) { fired++ })
+
+	// An event that was never scheduled isn't in the queue, cancelling it can't remove another one
+	var zero Event
+	testingHelp.Equals(t, false, zero.Scheduled())
+	cpu.Cancel(&zero)
+	testingHelp.Equals(t, true, scheduled.Scheduled())
+
+	testingHelp.NotNil(t, cpu.RunFor(10, UnitCycles))
+	testingHelp.Equals(t, 1, fired)
+}
+
+func TestScheduler_Periodic(t *testing.T) {
+	cpu := newLoopCPU()
+
+	// Reschedules itself relative to the cycle it was meant to fire on, so it never drifts
+	var fired []uint64
+	var timer *Event
+	timer = cpu.ScheduleIn(7, func(cycle uint64) {
+		fired = append(fired, cycle)
+		cpu.Reschedule(timer, cycle+7)
+	})
+
+	testingHelp.NotNil(t, cpu.RunFor(30, UnitCycles))
+	testingHelp.Equals(t, []uint64{7, 14, 21, 28}, fired)
+}
+
+func TestScheduler_InterruptTakenAtBoundary(t *testing.T) {
+	cpu := newInterruptCPU()
+	_ = cpu.Mem.WriteByte(0x1000, 0xea) // NOP
+	cpu.PC = 0x1000
+
+	// An event raising IRQ is serviced on the same boundary
+	cpu.Schedule(1, func(cycle uint64) { cpu.AssertIRQ(0) })
+	result, err := cpu.Step()
+	testingHelp.NotNil(t, err)
+	testingHelp.Equals(t, IRQ, result.Interrupt)
+}
+
+func TestScheduler_WakesWAI(t *testing.T) {
+	cpu := newInterruptCPU()
+	cpu.SetVariant(WDC65C02)
+	_ = cpu.Mem.WriteByte(0x1000, 0xcb) // WAI
+	cpu.PC = 0x1000
+
+	// While waiting, events run on their exact cycle
+	cpu.Schedule(10, func(cycle uint64) { cpu.AssertIRQ(0) })
+	err := cpu.RunUntil(func(g6 *Go6502) bool { return g6.PC == irqHandler })
+	testingHelp.NotNil(t, err)
+	testingHelp.Equals(t, uint64(10+interruptCycles), cpu.Cycles)
+}
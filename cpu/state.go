@@ -0,0 +1,383 @@
+package cpu
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/pkg/errors"
+	"io"
+)
+
+/*
+Save states
+-----------
+SaveState writes the whole machine out in a binary format, LoadState reads it
+back in. Everything is little endian.
+
+Header:
+	[4]byte  Magic, "G65S"
+	uint16   Version, currently 1
+
+CPU (cpuState below, field by field):
+	byte     Variant
+	byte     A, X, Y, SP
+	uint16   PC
+	byte     Status, as pushed by PHP
+	byte     DecimalMode, MagicConstant
+	uint64   Cycles, Instructions
+	bool     Pending software interrupt
+	byte     Software interrupt type, 0 none 1 RST 2 BRK
+	uint64   IRQ lines, NMI lines
+	bool     NMI pending, I flag before the last instruction
+	byte     Opcode of the last instruction
+	bool     Halted, Waiting
+
+6510 I/O port:
+	bool     Present, the rest of the section is left out if false
+	byte     DataDirection, Data, PullUps, ExternalMask, ExternalInputs
+	uint64   FalloffCycles
+	byte     Charged lines
+	[8]uint64 Cycle each line started floating on
+
+Memory:
+	[65536]byte
+
+Addons, only ones implementing StatefulAddon:
+	uint16   Count
+	Then for each addon:
+	uint16   Name length, followed by the name
+	uint32   State length, followed by the state
+
+Only Mem is saved, memory and devices behind a Bus need a StatefulAddon to be
+saved. Callbacks like IOPort.OnChange aren't saved, they stay as they are. Neither
+are scheduled events, addons should reschedule theirs in LoadState. Addons are
+matched up by name, an addon in the state that isn't registered is an error.
+*/
+
+// StatefulAddon is an addon with state that should go in save states
+type StatefulAddon interface {
+	Addon
+	// StateName identifies the addon in a save state, it should never change
+	StateName() string
+	SaveState(w io.Writer) error
+	LoadState(r io.Reader) error
+}
+
+var stateMagic = [4]byte{'G', '6', '5', 'S'}
+
+// StateVersion is bumped whenever the save state format changes
+const StateVersion = 1
+
+var interruptTypeCodes = map[string]byte{"": 0, RST: 1, BRK: 2}
+var interruptCodeTypes = map[byte]string{0: "", 1: RST, 2: BRK}
+
+type stateHeader struct {
+	Magic   [4]byte
+	Version uint16
+}
+
+type cpuState struct {
+	Variant                byte
+	A, X, Y, SP            byte
+	PC                     uint16
+	Status                 byte
+	DecimalMode            byte
+	MagicConstant          byte
+	Cycles, Instructions   uint64
+	InterruptOccurred      bool
+	InterruptType          byte
+	IRQLines, NMILines     uint64
+	NMIPending             bool
+	InterruptDisableBefore bool
+	Opcode                 byte
+	Halted, Waiting        bool
+}
+
+type portState struct {
+	DataDirection, Data, PullUps, ExternalMask, ExternalInputs byte
+	FalloffCycles                                              uint64
+	Charged                                                    byte
+	FloatingSince                                              [8]uint64
+}
+
+func (g6 *Go6502) SaveState(w io.Writer) (err error) {
+	// Safe to call while running, the state is taken between instructions
+	g6.Inspect(func(g6 *Go6502) {
+		err = g6.saveState(w)
+	})
+	return err
+}
+
+func (g6 *Go6502) LoadState(r io.Reader) (err error) {
+	// Nothing is changed unless the whole state can be read, but an addon that
+	// fails to load its own state leaves the CPU and memory already restored
+	g6.Inspect(func(g6 *Go6502) {
+		err = g6.loadState(r)
+	})
+	return err
+}
+
+func (g6 *Go6502) captureState() cpuState {
+	return cpuState{
+		Variant: byte(g6.variant),
+		A:       g6.A, X: g6.X, Y: g6.Y, SP: g6.SP,
+		PC:                     g6.PC,
+		Status:                 g6.Stat.AsByte(true),
+		DecimalMode:            byte(g6.DecimalMode),
+		MagicConstant:          g6.MagicConstant,
+		Cycles:                 g6.Cycles,
+		Instructions:           g6.Instructions,
+		InterruptOccurred:      g6.interruptOccurred,
+		InterruptType:          interruptTypeCodes[g6.currentInterruptType],
+		IRQLines:               g6.irqLines,
+		NMILines:               g6.nmiLines,
+		NMIPending:             g6.nmiPending,
+		InterruptDisableBefore: g6.interruptDisableBefore,
+		Opcode:                 g6.CurrentInstruction.Opcode,
+		Halted:                 g6.halted,
+		Waiting:                g6.waiting,
+	}
+}
+
+func (g6 *Go6502) restoreState(state cpuState) {
+	// Leaves the variant alone, switch it first if it changed
+	g6.A, g6.X, g6.Y, g6.SP = state.A, state.X, state.Y, state.SP
+	g6.PC = state.PC
+	g6.Stat.FromByte(state.Status)
+	g6.DecimalMode = DecimalMode(state.DecimalMode)
+	g6.MagicConstant = state.MagicConstant
+	g6.Cycles = state.Cycles
+	g6.Instructions = state.Instructions
+	g6.interruptOccurred = state.InterruptOccurred
+	g6.currentInterruptType = interruptCodeTypes[state.InterruptType]
+	g6.irqLines = state.IRQLines
+	g6.nmiLines = state.NMILines
+	g6.nmiPending = state.NMIPending
+	g6.interruptDisableBefore = state.InterruptDisableBefore
+	g6.CurrentInstruction = g6.instructionSet()[state.Opcode]
+	g6.halted = state.Halted
+	g6.waiting = state.Waiting
+}
+
+func (p *IOPort) captureState() portState {
+	return portState{
+		DataDirection:  p.DataDirection,
+		Data:           p.Data,
+		PullUps:        p.PullUps,
+		ExternalMask:   p.ExternalMask,
+		ExternalInputs: p.ExternalInputs,
+		FalloffCycles:  p.FalloffCycles,
+		Charged:        p.charged,
+		FloatingSince:  p.floatingSince,
+	}
+}
+
+func (p *IOPort) restoreState(state portState) {
+	p.DataDirection = state.DataDirection
+	p.Data = state.Data
+	p.PullUps = state.PullUps
+	p.ExternalMask = state.ExternalMask
+	p.ExternalInputs = state.ExternalInputs
+	p.FalloffCycles = state.FalloffCycles
+	p.charged = state.Charged
+	p.floatingSince = state.FloatingSince
+}
+
+func (g6 *Go6502) restorePort(state portState, before byte) {
+	// Restores the port, telling OnChange if the lines end up different from before
+	g6.port.restoreState(state)
+
+	if after := g6.port.Lines(g6.Cycles); after != before && g6.port.OnChange != nil {
+		g6.port.OnChange(after)
+	}
+}
+
+func (g6 *Go6502) saveState(w io.Writer) (err error) {
+	if err = binary.Write(w, binary.LittleEndian, stateHeader{stateMagic, StateVersion}); err != nil {
+		return errors.Wrap(err, "Error writing save state header")
+	}
+
+	state := g6.captureState()
+	if err = binary.Write(w, binary.LittleEndian, state); err != nil {
+		return errors.Wrap(err, "Error writing CPU state")
+	}
+
+	if err = binary.Write(w, binary.LittleEndian, g6.port != nil); err != nil {
+		return errors.Wrap(err, "Error writing I/O port state")
+	}
+	if g6.port != nil {
+		port := g6.port.captureState()
+		if err = binary.Write(w, binary.LittleEndian, port); err != nil {
+			return errors.Wrap(err, "Error writing I/O port state")
+		}
+	}
+
+	if _, err = w.Write(g6.Mem.Mem[:]); err != nil {
+		return errors.Wrap(err, "Error writing memory")
+	}
+
+	return g6.saveAddonStates(w)
+}
+
+func (g6 *Go6502) statefulAddons() (addons map[string]StatefulAddon) {
+	addons = map[string]StatefulAddon{}
+	for _, addon := range g6.addons {
+		if stateful, ok := addon.(StatefulAddon); ok {
+			addons[stateful.StateName()] = stateful
+		}
+	}
+	return addons
+}
+
+func (g6 *Go6502) saveAddonStates(w io.Writer) (err error) {
+	var stateful []StatefulAddon
+	for _, addon := range g6.addons {
+		if s, ok := addon.(StatefulAddon); ok {
+			stateful = append(stateful, s)
+		}
+	}
+
+	if err = binary.Write(w, binary.LittleEndian, uint16(len(stateful))); err != nil {
+		return errors.Wrap(err, "Error writing addon count")
+	}
+
+	for _, addon := range stateful {
+		name := addon.StateName()
+
+		// Addon state is buffered so its length can go first
+		var buf bytes.Buffer
+		if err = addon.SaveState(&buf); err != nil {
+			return errors.Wrapf(err, "Error saving state of addon %v", name)
+		}
+
+		if err = binary.Write(w, binary.LittleEndian, uint16(len(name))); err != nil {
+			return errors.Wrapf(err, "Error writing state of addon %v", name)
+		}
+		if _, err = io.WriteString(w, name); err != nil {
+			return errors.Wrapf(err, "Error writing state of addon %v", name)
+		}
+		if err = binary.Write(w, binary.LittleEndian, uint32(buf.Len())); err != nil {
+			return errors.Wrapf(err, "Error writing state of addon %v", name)
+		}
+		if _, err = buf.WriteTo(w); err != nil {
+			return errors.Wrapf(err, "Error writing state of addon %v", name)
+		}
+	}
+
+	return nil
+}
+
+func (g6 *Go6502) loadState(r io.Reader) (err error) {
+	var header stateHeader
+	if err = binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return errors.Wrap(err, "Error reading save state header")
+	}
+	if header.Magic != stateMagic {
+		return errors.Errorf("Not a save state, magic was %#v", header.Magic)
+	}
+	if header.Version != StateVersion {
+		return errors.Errorf("Unsupported save state version %v, expected %v", header.Version, StateVersion)
+	}
+
+	var state cpuState
+	if err = binary.Read(r, binary.LittleEndian, &state); err != nil {
+		return errors.Wrap(err, "Error reading CPU state")
+	}
+	if int(state.Variant) >= len(variants) {
+		return errors.Errorf("Unknown variant %#v in save state", state.Variant)
+	}
+	if _, ok := interruptCodeTypes[state.InterruptType]; !ok {
+		return errors.Errorf("Unknown interrupt type %#v in save state", state.InterruptType)
+	}
+
+	var hasPort bool
+	var port portState
+	if err = binary.Read(r, binary.LittleEndian, &hasPort); err != nil {
+		return errors.Wrap(err, "Error reading I/O port state")
+	}
+	if hasPort {
+		if err = binary.Read(r, binary.LittleEndian, &port); err != nil {
+			return errors.Wrap(err, "Error reading I/O port state")
+		}
+	}
+
+	var mem [len(g6.Mem.Mem)]byte
+	if _, err = io.ReadFull(r, mem[:]); err != nil {
+		return errors.Wrap(err, "Error reading memory")
+	}
+
+	addonStates, err := g6.readAddonStates(r)
+	if err != nil {
+		return err
+	}
+
+	// Everything was read, start changing the CPU
+	// Switching variant replaces the I/O port, keep the old one and its callbacks if we can
+	if Variant(state.Variant) != g6.variant {
+		g6.SetVariant(Variant(state.Variant))
+	}
+
+	var lines byte
+	if g6.port != nil {
+		lines = g6.port.Lines(g6.Cycles)
+	}
+	g6.restoreState(state)
+	if g6.port != nil && hasPort {
+		g6.restorePort(port, lines)
+	}
+
+	g6.Mem.Mem = mem
+
+	addons := g6.statefulAddons()
+	for name, data := range addonStates {
+		if err = addons[name].LoadState(bytes.NewReader(data)); err != nil {
+			return errors.Wrapf(err, "Error loading state of addon %v, the CPU and memory were already restored", name)
+		}
+	}
+
+	g6.clearHistory()
+	return nil
+}
+
+func (g6 *Go6502) readAddonStates(r io.Reader) (states map[string][]byte, err error) {
+	var count uint16
+	if err = binary.Read(r, binary.LittleEndian, &count); err != nil {
+		return nil, errors.Wrap(err, "Error reading addon count")
+	}
+
+	addons := g6.statefulAddons()
+	states = map[string][]byte{}
+	for i := uint16(0); i < count; i++ {
+		var nameLength uint16
+		if err = binary.Read(r, binary.LittleEndian, &nameLength); err != nil {
+			return nil, errors.Wrapf(err, "Error reading name of addon %v", i)
+		}
+
+		name := make([]byte, nameLength)
+		if _, err = io.ReadFull(r, name); err != nil {
+			return nil, errors.Wrapf(err, "Error reading name of addon %v", i)
+		}
+
+		if _, ok := addons[string(name)]; !ok {
+			return nil, errors.Errorf("Save state has addon %v, but it isn't registered", string(name))
+		}
+
+		var stateLength uint32
+		if err = binary.Read(r, binary.LittleEndian, &stateLength); err != nil {
+			return nil, errors.Wrapf(err, "Error reading state of addon %v", string(name))
+		}
+
+		// The buffer only grows as data arrives, a corrupt length can't allocate gigabytes up front
+		var state bytes.Buffer
+		n, err := io.Copy(&state, io.LimitReader(r, int64(stateLength)))
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error reading state of addon %v", string(name))
+		}
+		if n != int64(stateLength) {
+			return nil, errors.Errorf("State of addon %v is %v bytes, expected %v", string(name), n, stateLength)
+		}
+
+		states[string(name)] = state.Bytes()
+	}
+
+	return states, nil
+}
@@ -0,0 +1,206 @@
+package cpu
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/edison-moreland/go6502/testingHelp"
+	"io"
+	"strings"
+	"testing"
+)
+
+// Mixes the registers, stack, memory and an IRQ handler
+var stateProgram = []byte{
+	0x58,             // $1000 CLI
+	0xe8,             // $1001 INX
+	0x8a,             // $1002 TXA
+	0x48,             // $1003 PHA
+	0x68,             // $1004 PLA
+	0x6d, 0x00, 0x20, // $1005 ADC $2000
+	0x8d, 0x00, 0x20, // $1008 STA $2000
+	0x4c, 0x01, 0x10, // $100B JMP $1001
+}
+
+// Counts instructions, and saves the count
+type counterAddon struct {
+	BaseAddon
+	count uint32
+}
+
+func (ca *counterAddon) AfterExecution() {
+	ca.count++
+}
+
+func (ca *counterAddon) StateName() string {
+	return "counter"
+}
+
+func (ca *counterAddon) SaveState(w io.Writer) error {
+	return binary.Write(w, binary.LittleEndian, ca.count)
+}
+
+func (ca *counterAddon) LoadState(r io.Reader) error {
+	return binary.Read(r, binary.LittleEndian, &ca.count)
+}
+
+func newStateCPU(variant Variant) (*Go6502, *counterAddon) {
+	cpu := New(WithVariant(variant))
+	counter := &counterAddon{}
+	cpu.RegisterAddons(counter)
+
+	for i, programByte := range stateProgram {
+		_ = cpu.Mem.WriteByte(0x1000+uint16(i), programByte)
+	}
+
+	// IRQ handler releases the line and returns
+	_ = cpu.Mem.WriteWord(0xFFFE, 0x3000)
+	_ = cpu.Mem.WriteByte(0x3000, 0x40) // RTI
+	cpu.PC = 0x1000
+	cpu.SP = 0xFF
+	return cpu, counter
+}
+
+func TestGo6502_SaveStateRoundTrip(t *testing.T) {
+	for _, variant := range []Variant{NMOS6502, MOS6510, WDC65C02} {
+		original, originalCounter := newStateCPU(variant)
+		testingHelp.NotNil(t, original.RunFor(37, UnitInstructions))
+		original.AssertIRQ(3)
+		if port := original.Port(); port != nil {
+			_ = original.writeByte(IOPortDataDirection, 0x2F)
+			_ = original.writeByte(IOPortData, 0x35)
+		}
+
+		var state bytes.Buffer
+		testingHelp.NotNil(t, original.SaveState(&state))
+
+		// Restore into a CPU that has been doing something else entirely
+		restored, restoredCounter := newStateCPU(NMOS6502)
+		restored.A = 0x99
+		testingHelp.NotNil(t, restored.RunFor(5, UnitInstructions))
+		testingHelp.NotNil(t, restored.LoadState(&state))
+		testingHelp.Equals(t, variant, restored.Variant())
+		testingHelp.Equals(t, originalCounter.count, restoredCounter.count)
+
+		// Both should carry on identically
+		for i := 0; i < 200; i++ {
+			if i == 100 {
+				original.ReleaseIRQ(3)
+				restored.ReleaseIRQ(3)
+			}
+
+			testingHelp.NotNil(t, original.RunFor(1, UnitInstructions))
+			testingHelp.NotNil(t, restored.RunFor(1, UnitInstructions))
+			testingHelp.Equals(t, original.Snapshot(), restored.Snapshot())
+		}
+		testingHelp.Equals(t, originalCounter.count, restoredCounter.count)
+
+		if original.Port() != nil {
+			testingHelp.Equals(t, original.Port().Lines(original.Cycles), restored.Port().Lines(restored.Cycles))
+		}
+	}
+}
+
+func TestGo6502_LoadStateBadHeader(t *testing.T) {
+	cpu := New()
+	cpu.A = 0x42
+
+	err := cpu.LoadState(bytes.NewReader([]byte("not a save state")))
+	testingHelp.Assert(t, err != nil, "expected bad magic to fail")
+
+	var state bytes.Buffer
+	testingHelp.NotNil(t, New().SaveState(&state))
+	raw := state.Bytes()
+	raw[4] = StateVersion + 1
+	err = cpu.LoadState(bytes.NewReader(raw))
+	testingHelp.Assert(t, err != nil, "expected newer version to fail")
+
+	// Truncated states don't change anything
+	raw[4] = StateVersion
+	err = cpu.LoadState(bytes.NewReader(raw[:len(raw)-100]))
+	testingHelp.Assert(t, err != nil, "expected truncated state to fail")
+	testingHelp.Equals(t, byte(0x42), cpu.A)
+}
+
+func TestGo6502_LoadStateUnknownAddon(t *testing.T) {
+	original, _ := newStateCPU(NMOS6502)
+	var state bytes.Buffer
+	testingHelp.NotNil(t, original.SaveState(&state))
+
+	err := New().LoadState(&state)
+	testingHelp.Assert(t, err != nil, "expected unregistered addon to fail")
+}
+
+func TestGo6502_LoadStatePortOnChange(t *testing.T) {
+	cpu, _ := newStateCPU(MOS6510)
+	cpu.Port().PullUps = 0x17
+	var changes []byte
+	cpu.Port().OnChange = func(lines byte) { changes = append(changes, lines) }
+
+	// Save with BASIC and KERNAL banked out
+	_ = cpu.writeByte(IOPortDataDirection, 0x2F)
+	_ = cpu.writeByte(IOPortData, 0x34)
+	var state bytes.Buffer
+	testingHelp.NotNil(t, cpu.SaveState(&state))
+
+	_ = cpu.writeByte(IOPortData, 0x37)
+	changes = nil
+
+	// Loading puts the lines back, whoever banks on them has to hear about it
+	testingHelp.NotNil(t, cpu.LoadState(&state))
+	testingHelp.Equals(t, []byte{0x34 | 0x10}, changes)
+
+	// Nothing changes, nothing is reported
+	changes = nil
+	state.Reset()
+	testingHelp.NotNil(t, cpu.SaveState(&state))
+	testingHelp.NotNil(t, cpu.LoadState(&state))
+	testingHelp.Equals(t, []byte(nil), changes)
+}
+
+func TestGo6502_LoadStateCorruptAddonLength(t *testing.T) {
+	cpu, counter := newStateCPU(NMOS6502)
+	counter.count = 7
+	var state bytes.Buffer
+	testingHelp.NotNil(t, cpu.SaveState(&state))
+
+	// The counter's state is last, a 4 byte length then its 4 bytes
+	raw := state.Bytes()
+	binary.LittleEndian.PutUint32(raw[len(raw)-8:], 0xFFFFFFFF)
+
+	counter.count = 0
+	err := cpu.LoadState(bytes.NewReader(raw))
+	testingHelp.Assert(t, err != nil, "expected a length past the end of the state to fail")
+	testingHelp.Equals(t, uint32(0), counter.count)
+}
+
+// Saves nothing, and can't load anything
+type brokenAddon struct {
+	BaseAddon
+}
+
+func (ba *brokenAddon) StateName() string {
+	return "broken"
+}
+
+func (ba *brokenAddon) SaveState(w io.Writer) error {
+	return nil
+}
+
+func (ba *brokenAddon) LoadState(r io.Reader) error {
+	return io.ErrUnexpectedEOF
+}
+
+func TestGo6502_LoadStateAddonFails(t *testing.T) {
+	cpu, _ := newStateCPU(NMOS6502)
+	cpu.RegisterAddons(&brokenAddon{})
+	cpu.A = 0x42
+	var state bytes.Buffer
+	testingHelp.NotNil(t, cpu.SaveState(&state))
+
+	// Addons load last, the CPU has already been restored by then
+	cpu.A = 0
+	err := cpu.LoadState(&state)
+	testingHelp.Assert(t, err != nil, "expected an addon that can't load to fail")
+	testingHelp.Assert(t, strings.Contains(err.Error(), "already restored"), "expected error to say the CPU was restored, got %v", err)
+	testingHelp.Equals(t, byte(0x42), cpu.A)
+}
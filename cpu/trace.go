@@ -0,0 +1,58 @@
+package cpu
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+/*
+Tracing
+-------
+The tracer writes a line for every instruction before it executes, in the same
+layout as nestest.log:
+
+C000  4C F5 C5  JMP $C5F5                       A:00 X:00 Y:00 P:24 SP:FD CYC:7
+
+PC, the instruction bytes, the disassembly, then the registers and the total
+cycle count. Undocumented opcodes are marked with a * before the mnemonic. The
+PPU column from nestest.log is left out, DiffTrace skips over it.
+
+Output isn't buffered, wrap slow writers in a bufio.Writer.
+*/
+
+func (g6 *Go6502) SetTracer(w io.Writer) {
+	// Traces every instruction to w, nil turns tracing off
+	g6.tracer = w
+}
+
+// WithTracer traces every instruction to w
+func WithTracer(w io.Writer) Option {
+	return func(g6 *Go6502) {
+		g6.SetTracer(w)
+	}
+}
+
+func (g6 *Go6502) TraceLine() string {
+	// Trace line for the instruction at PC, without a newline
+	text, size := g6.disassemble(g6.PC, true)
+
+	instructionBytes := make([]string, size)
+	for i := range instructionBytes {
+		instructionBytes[i] = fmt.Sprintf("%02X", g6.peekByte(g6.PC+uint16(i)))
+	}
+
+	marker := ' '
+	if g6.isUndocumented(g6.peekByte(g6.PC)) {
+		marker = '*'
+	}
+
+	return fmt.Sprintf("%04X  %-9s%c%-31s A:%02X X:%02X Y:%02X P:%02X SP:%02X CYC:%d",
+		g6.PC, strings.Join(instructionBytes, " "), marker, text,
+		g6.A, g6.X, g6.Y, g6.Stat.AsByte(false), g6.SP, g6.Cycles)
+}
+
+func (g6 *Go6502) trace() (err error) {
+	_, err = io.WriteString(g6.tracer, g6.TraceLine()+"\n")
+	return err
+}
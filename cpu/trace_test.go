@@ -0,0 +1,159 @@
+package cpu
+
+import (
+	"bytes"
+	"github.com/edison-moreland/go6502/testingHelp"
+	"strings"
+	"testing"
+)
+
+type traceTestData struct {
+	description string
+	setup       func(cpu *Go6502)
+	program     []byte
+	expected    string
+}
+
+var traceTests = []traceTestData{
+	{
+		"JMP absolute",
+		func(cpu *Go6502) {},
+		[]byte{0x4c, 0xf5, 0xc5},
+		"C000  4C F5 C5  JMP $C5F5                       A:00 X:00 Y:00 P:24 SP:FD CYC:7",
+	},
+	{
+		"Immediate",
+		func(cpu *Go6502) {},
+		[]byte{0xa2, 0x00},
+		"C000  A2 00     LDX #$00                        A:00 X:00 Y:00 P:24 SP:FD CYC:7",
+	},
+	{
+		"Zeropage",
+		func(cpu *Go6502) { cpu.Mem.Mem[0x00] = 0x12 },
+		[]byte{0x86, 0x00},
+		"C000  86 00     STX $00 = 12                    A:00 X:00 Y:00 P:24 SP:FD CYC:7",
+	},
+	{
+		"Indirect Y",
+		func(cpu *Go6502) {
+			cpu.Y = 0x04
+			_ = cpu.Mem.WriteWord(0x89, 0x0300)
+			cpu.Mem.Mem[0x0304] = 0x89
+		},
+		[]byte{0xb1, 0x89},
+		"C000  B1 89     LDA ($89),Y = 0300 @ 0304 = 89  A:00 X:00 Y:04 P:24 SP:FD CYC:7",
+	},
+	{
+		"Indirect X wraps in zeropage",
+		func(cpu *Go6502) {
+			cpu.X = 0x01
+			cpu.Mem.Mem[0x00] = 0x02
+			cpu.Mem.Mem[0xff] = 0x00
+			cpu.Mem.Mem[0x0200] = 0x5a
+		},
+		[]byte{0xa1, 0xfe},
+		"C000  A1 FE     LDA ($FE,X) @ FF = 0200 = 5A    A:00 X:01 Y:00 P:24 SP:FD CYC:7",
+	},
+	{
+		"Branch",
+		func(cpu *Go6502) {},
+		[]byte{0xb0, 0xfe},
+		"C000  B0 FE     BCS $C000                       A:00 X:00 Y:00 P:24 SP:FD CYC:7",
+	},
+	{
+		"Undocumented",
+		func(cpu *Go6502) {},
+		[]byte{0x04, 0xa9},
+		"C000  04 A9    *NOP $A9 = 00                    A:00 X:00 Y:00 P:24 SP:FD CYC:7",
+	},
+	{
+		"Accumulator",
+		func(cpu *Go6502) {},
+		[]byte{0x4a},
+		"C000  4A        LSR A                           A:00 X:00 Y:00 P:24 SP:FD CYC:7",
+	},
+}
+
+func newTraceCPU() *Go6502 {
+	// Same state nestest starts in
+	cpu := New()
+	cpu.PC = 0xC000
+	cpu.SP = 0xFD
+	cpu.Stat.InterruptDisable = true
+	cpu.Cycles = 7
+	return cpu
+}
+
+func TestGo6502_TraceLine(t *testing.T) {
+	for _, testData := range traceTests {
+		cpu := newTraceCPU()
+		testData.setup(cpu)
+		for i, programByte := range testData.program {
+			_ = cpu.Mem.WriteByte(0xC000+uint16(i), programByte)
+		}
+
+		line := cpu.TraceLine()
+		testingHelp.Assert(t, line == testData.expected, "%v:\nexpected %v\ngot      %v", testData.description, testData.expected, line)
+	}
+}
+
+func TestGo6502_Tracer(t *testing.T) {
+	var trace bytes.Buffer
+	cpu := newTraceCPU()
+	cpu.SetTracer(&trace)
+	_ = cpu.Mem.WriteByte(0xC000, 0xa2) // LDX #$42
+	_ = cpu.Mem.WriteByte(0xC001, 0x42)
+	_ = cpu.Mem.WriteByte(0xC002, 0xe8) // INX
+
+	testingHelp.NotNil(t, cpu.RunFor(2, UnitInstructions))
+	testingHelp.Equals(t, ""+
+		"C000  A2 42     LDX #$42                        A:00 X:00 Y:00 P:24 SP:FD CYC:7\n"+
+		"C002  E8        INX                             A:00 X:42 Y:00 P:24 SP:FD CYC:9\n",
+		trace.String())
+}
+
+const referenceTrace = `C000  4C F5 C5  JMP $C5F5                       A:00 X:00 Y:00 P:24 SP:FD PPU:  0, 21 CYC:7
+C5F5  A2 00     LDX #$00                        A:00 X:00 Y:00 P:24 SP:FD PPU:  0, 30 CYC:10
+C5F7  86 00     STX $00 = 00                    A:00 X:00 Y:00 P:26 SP:FD PPU:  0, 36 CYC:12
+`
+
+func TestDiffTrace_Match(t *testing.T) {
+	ours := strings.Replace(referenceTrace, "PPU:  0, 21 ", "", -1)
+	divergence, err := DiffTrace(strings.NewReader(ours), strings.NewReader(referenceTrace), DiffOptions{})
+	testingHelp.NotNil(t, err)
+	testingHelp.Assert(t, divergence == nil, "expected no divergence, got %v", divergence)
+}
+
+func TestDiffTrace_Divergence(t *testing.T) {
+	ours := strings.Replace(referenceTrace, "P:26", "P:24", 1)
+	divergence, err := DiffTrace(strings.NewReader(ours), strings.NewReader(referenceTrace), DiffOptions{})
+	testingHelp.NotNil(t, err)
+	testingHelp.Assert(t, divergence != nil, "expected a divergence")
+	testingHelp.Equals(t, 3, divergence.Line)
+	testingHelp.Equals(t, "P", divergence.Field)
+}
+
+func TestDiffTrace_Options(t *testing.T) {
+	ours := strings.Replace(referenceTrace, "CYC:10", "CYC:11", 1)
+	ours = strings.Replace(ours, "STX $00 = 00", "STX $00 = FF", 1)
+
+	divergence, err := DiffTrace(strings.NewReader(ours), strings.NewReader(referenceTrace), DiffOptions{})
+	testingHelp.NotNil(t, err)
+	testingHelp.Equals(t, "CYC", divergence.Field)
+
+	divergence, err = DiffTrace(strings.NewReader(ours), strings.NewReader(referenceTrace), DiffOptions{IgnoreCycles: true})
+	testingHelp.NotNil(t, err)
+	testingHelp.Equals(t, "Disassembly", divergence.Field)
+
+	divergence, err = DiffTrace(strings.NewReader(ours), strings.NewReader(referenceTrace), DiffOptions{IgnoreCycles: true, IgnoreDisassembly: true})
+	testingHelp.NotNil(t, err)
+	testingHelp.Assert(t, divergence == nil, "expected no divergence, got %v", divergence)
+}
+
+func TestDiffTrace_Length(t *testing.T) {
+	ours := strings.SplitAfter(referenceTrace, "\n")[0]
+	divergence, err := DiffTrace(strings.NewReader(ours), strings.NewReader(referenceTrace), DiffOptions{})
+	testingHelp.NotNil(t, err)
+	testingHelp.Equals(t, 2, divergence.Line)
+	testingHelp.Equals(t, "Length", divergence.Field)
+}
@@ -0,0 +1,108 @@
+package cpu
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// DiffOptions picks which parts of a trace line DiffTrace compares
+type DiffOptions struct {
+	// IgnoreDisassembly skips the disassembly and memory annotations
+	IgnoreDisassembly bool
+	// IgnoreCycles skips the cycle count, for logs that start counting somewhere else
+	IgnoreCycles bool
+}
+
+// TraceDivergence is the first place two traces disagree
+type TraceDivergence struct {
+	// Line number, starting at 1
+	Line int
+	// Field that was different, "PC", "A", "CYC" etc
+	Field           string
+	Ours, Reference string
+}
+
+func (d *TraceDivergence) String() string {
+	return fmt.Sprintf("Line %v, %v differs\n ours: %v\n  ref: %v", d.Line, d.Field, d.Ours, d.Reference)
+}
+
+var traceRegisters = regexp.MustCompile(`A:([0-9A-Fa-f]{2}) X:([0-9A-Fa-f]{2}) Y:([0-9A-Fa-f]{2}) P:([0-9A-Fa-f]{2}) SP:([0-9A-Fa-f]{2})`)
+var traceCycles = regexp.MustCompile(`CYC:\s*(\d+)`)
+
+// Names of the fields in a parsed trace line, in the order they are compared
+var traceFields = []string{"PC", "Bytes", "Disassembly", "A", "X", "Y", "P", "SP", "CYC"}
+
+func parseTraceLine(line string) (fields []string, err error) {
+	registers := traceRegisters.FindStringSubmatchIndex(line)
+	if len(line) < 16 || registers == nil {
+		return nil, errors.Errorf("Couldn't parse trace line %#v", line)
+	}
+
+	fields = []string{
+		strings.TrimSpace(line[0:4]),
+		strings.TrimSpace(line[6:15]),
+		strings.TrimSpace(line[15:registers[0]]),
+	}
+	for i := 2; i < len(registers); i += 2 {
+		fields = append(fields, line[registers[i]:registers[i+1]])
+	}
+
+	// PPU and anything else between the registers and CYC is skipped
+	cycles := traceCycles.FindStringSubmatch(line)
+	if cycles == nil {
+		fields = append(fields, "")
+	} else {
+		fields = append(fields, cycles[1])
+	}
+
+	return fields, nil
+}
+
+func DiffTrace(ours, reference io.Reader, options DiffOptions) (divergence *TraceDivergence, err error) {
+	// Finds the first line where ours doesn't match reference, nil if they match all the way through
+	oursScanner := bufio.NewScanner(ours)
+	referenceScanner := bufio.NewScanner(reference)
+
+	for line := 1; ; line++ {
+		oursOk := oursScanner.Scan()
+		referenceOk := referenceScanner.Scan()
+
+		if !oursOk || !referenceOk {
+			if err = oursScanner.Err(); err != nil {
+				return nil, errors.Wrap(err, "Error reading our trace")
+			}
+			if err = referenceScanner.Err(); err != nil {
+				return nil, errors.Wrap(err, "Error reading reference trace")
+			}
+
+			if oursOk != referenceOk {
+				return &TraceDivergence{line, "Length", oursScanner.Text(), referenceScanner.Text()}, nil
+			}
+			return nil, nil
+		}
+
+		oursLine, referenceLine := oursScanner.Text(), referenceScanner.Text()
+		oursFields, err := parseTraceLine(oursLine)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error parsing line %v of our trace", line)
+		}
+		referenceFields, err := parseTraceLine(referenceLine)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error parsing line %v of reference trace", line)
+		}
+
+		for i, name := range traceFields {
+			if (name == "Disassembly" && options.IgnoreDisassembly) || (name == "CYC" && options.IgnoreCycles) {
+				continue
+			}
+
+			if !strings.EqualFold(oursFields[i], referenceFields[i]) {
+				return &TraceDivergence{line, name, oursLine, referenceLine}, nil
+			}
+		}
+	}
+}
@@ -0,0 +1,392 @@
+package cpu
+
+/*
+Undocumented opcodes
+--------------------
+The NMOS 6502 decodes every one of its 256 opcodes, the ones MOS never
+documented run a mix of the circuits used by the documented instructions.
+Most of them are stable and plenty of C64 software relies on them.
+
+XAA and LAX immediate are unstable, part of their result depends on analog
+effects that vary from chip to chip. They are emulated using Go6502.MagicConstant.
+
+The JAM opcodes lock up the CPU until it is reset.
+http://www.oxyron.de/html/opcodes02.html
+https://www.masswerk.at/6502/6502_instruction_set.html#illegals
+*/
+
+var UndocumentedInstructionSet = map[byte]Instruction{
+	0x02: {0x02, OpJAM, ModeIMP, 1, 2, false},
+	0x12: {0x12, OpJAM, ModeIMP, 1, 2, false},
+	0x22: {0x22, OpJAM, ModeIMP, 1, 2, false},
+	0x32: {0x32, OpJAM, ModeIMP, 1, 2, false},
+	0x42: {0x42, OpJAM, ModeIMP, 1, 2, false},
+	0x52: {0x52, OpJAM, ModeIMP, 1, 2, false},
+	0x62: {0x62, OpJAM, ModeIMP, 1, 2, false},
+	0x72: {0x72, OpJAM, ModeIMP, 1, 2, false},
+	0x92: {0x92, OpJAM, ModeIMP, 1, 2, false},
+	0xb2: {0xb2, OpJAM, ModeIMP, 1, 2, false},
+	0xd2: {0xd2, OpJAM, ModeIMP, 1, 2, false},
+	0xf2: {0xf2, OpJAM, ModeIMP, 1, 2, false},
+	0x1a: {0x1a, OpNOP, ModeIMP, 1, 2, false},
+	0x3a: {0x3a, OpNOP, ModeIMP, 1, 2, false},
+	0x5a: {0x5a, OpNOP, ModeIMP, 1, 2, false},
+	0x7a: {0x7a, OpNOP, ModeIMP, 1, 2, false},
+	0xda: {0xda, OpNOP, ModeIMP, 1, 2, false},
+	0xfa: {0xfa, OpNOP, ModeIMP, 1, 2, false},
+	0x80: {0x80, OpNOP, ModeIMM, 2, 2, false},
+	0x82: {0x82, OpNOP, ModeIMM, 2, 2, false},
+	0x89: {0x89, OpNOP, ModeIMM, 2, 2, false},
+	0xc2: {0xc2, OpNOP, ModeIMM, 2, 2, false},
+	0xe2: {0xe2, OpNOP, ModeIMM, 2, 2, false},
+	0x04: {0x04, OpNOP, ModeZP, 2, 3, false},
+	0x44: {0x44, OpNOP, ModeZP, 2, 3, false},
+	0x64: {0x64, OpNOP, ModeZP, 2, 3, false},
+	0x14: {0x14, OpNOP, ModeZPX, 2, 4, false},
+	0x34: {0x34, OpNOP, ModeZPX, 2, 4, false},
+	0x54: {0x54, OpNOP, ModeZPX, 2, 4, false},
+	0x74: {0x74, OpNOP, ModeZPX, 2, 4, false},
+	0xd4: {0xd4, OpNOP, ModeZPX, 2, 4, false},
+	0xf4: {0xf4, OpNOP, ModeZPX, 2, 4, false},
+	0x0c: {0x0c, OpNOP, ModeABS, 3, 4, false},
+	0x1c: {0x1c, OpNOP, ModeABSX, 3, 4, true},
+	0x3c: {0x3c, OpNOP, ModeABSX, 3, 4, true},
+	0x5c: {0x5c, OpNOP, ModeABSX, 3, 4, true},
+	0x7c: {0x7c, OpNOP, ModeABSX, 3, 4, true},
+	0xdc: {0xdc, OpNOP, ModeABSX, 3, 4, true},
+	0xfc: {0xfc, OpNOP, ModeABSX, 3, 4, true},
+	0x07: {0x07, OpSLO, ModeZP, 2, 5, false},
+	0x17: {0x17, OpSLO, ModeZPX, 2, 6, false},
+	0x0f: {0x0f, OpSLO, ModeABS, 3, 6, false},
+	0x1f: {0x1f, OpSLO, ModeABSX, 3, 7, false},
+	0x1b: {0x1b, OpSLO, ModeABSY, 3, 7, false},
+	0x03: {0x03, OpSLO, ModeINDX, 2, 8, false},
+	0x13: {0x13, OpSLO, ModeINDY, 2, 8, false},
+	0x27: {0x27, OpRLA, ModeZP, 2, 5, false},
+	0x37: {0x37, OpRLA, ModeZPX, 2, 6, false},
+	0x2f: {0x2f, OpRLA, ModeABS, 3, 6, false},
+	0x3f: {0x3f, OpRLA, ModeABSX, 3, 7, false},
+	0x3b: {0x3b, OpRLA, ModeABSY, 3, 7, false},
+	0x23: {0x23, OpRLA, ModeINDX, 2, 8, false},
+	0x33: {0x33, OpRLA, ModeINDY, 2, 8, false},
+	0x47: {0x47, OpSRE, ModeZP, 2, 5, false},
+	0x57: {0x57, OpSRE, ModeZPX, 2, 6, false},
+	0x4f: {0x4f, OpSRE, ModeABS, 3, 6, false},
+	0x5f: {0x5f, OpSRE, ModeABSX, 3, 7, false},
+	0x5b: {0x5b, OpSRE, ModeABSY, 3, 7, false},
+	0x43: {0x43, OpSRE, ModeINDX, 2, 8, false},
+	0x53: {0x53, OpSRE, ModeINDY, 2, 8, false},
+	0x67: {0x67, OpRRA, ModeZP, 2, 5, false},
+	0x77: {0x77, OpRRA, ModeZPX, 2, 6, false},
+	0x6f: {0x6f, OpRRA, ModeABS, 3, 6, false},
+	0x7f: {0x7f, OpRRA, ModeABSX, 3, 7, false},
+	0x7b: {0x7b, OpRRA, ModeABSY, 3, 7, false},
+	0x63: {0x63, OpRRA, ModeINDX, 2, 8, false},
+	0x73: {0x73, OpRRA, ModeINDY, 2, 8, false},
+	0x87: {0x87, OpSAX, ModeZP, 2, 3, false},
+	0x97: {0x97, OpSAX, ModeZPY, 2, 4, false},
+	0x8f: {0x8f, OpSAX, ModeABS, 3, 4, false},
+	0x83: {0x83, OpSAX, ModeINDX, 2, 6, false},
+	0xa7: {0xa7, OpLAX, ModeZP, 2, 3, false},
+	0xb7: {0xb7, OpLAX, ModeZPY, 2, 4, false},
+	0xaf: {0xaf, OpLAX, ModeABS, 3, 4, false},
+	0xbf: {0xbf, OpLAX, ModeABSY, 3, 4, true},
+	0xa3: {0xa3, OpLAX, ModeINDX, 2, 6, false},
+	0xb3: {0xb3, OpLAX, ModeINDY, 2, 5, true},
+	0xab: {0xab, OpLXA, ModeIMM, 2, 2, false},
+	0xc7: {0xc7, OpDCP, ModeZP, 2, 5, false},
+	0xd7: {0xd7, OpDCP, ModeZPX, 2, 6, false},
+	0xcf: {0xcf, OpDCP, ModeABS, 3, 6, false},
+	0xdf: {0xdf, OpDCP, ModeABSX, 3, 7, false},
+	0xdb: {0xdb, OpDCP, ModeABSY, 3, 7, false},
+	0xc3: {0xc3, OpDCP, ModeINDX, 2, 8, false},
+	0xd3: {0xd3, OpDCP, ModeINDY, 2, 8, false},
+	0xe7: {0xe7, OpISC, ModeZP, 2, 5, false},
+	0xf7: {0xf7, OpISC, ModeZPX, 2, 6, false},
+	0xef: {0xef, OpISC, ModeABS, 3, 6, false},
+	0xff: {0xff, OpISC, ModeABSX, 3, 7, false},
+	0xfb: {0xfb, OpISC, ModeABSY, 3, 7, false},
+	0xe3: {0xe3, OpISC, ModeINDX, 2, 8, false},
+	0xf3: {0xf3, OpISC, ModeINDY, 2, 8, false},
+	0x0b: {0x0b, OpANC, ModeIMM, 2, 2, false},
+	0x2b: {0x2b, OpANC, ModeIMM, 2, 2, false},
+	0x4b: {0x4b, OpALR, ModeIMM, 2, 2, false},
+	0x6b: {0x6b, OpARR, ModeIMM, 2, 2, false},
+	0xcb: {0xcb, OpSBX, ModeIMM, 2, 2, false},
+	0xeb: {0xeb, OpUSBC, ModeIMM, 2, 2, false},
+	0x8b: {0x8b, OpXAA, ModeIMM, 2, 2, false},
+	0xbb: {0xbb, OpLAS, ModeABSY, 3, 4, true},
+	0x9b: {0x9b, OpTAS, ModeABSY, 3, 5, false},
+	0x9f: {0x9f, OpSHA, ModeABSY, 3, 5, false},
+	0x93: {0x93, OpSHA, ModeINDY, 2, 6, false},
+	0x9c: {0x9c, OpSHY, ModeABSX, 3, 5, false},
+	0x9e: {0x9e, OpSHX, ModeABSY, 3, 5, false},
+}
+
+func init() {
+	// The NMOS 6502 runs undocumented opcodes like any other
+	for opcode, instruction := range UndocumentedInstructionSet {
+		InstructionSet[opcode] = instruction
+	}
+
+	for mnemonic, handler := range undocumentedHandlers {
+		InstructionHandlers[mnemonic] = handler
+	}
+}
+
+func storeHighByteAnd(g6 *Go6502, targetAddress uint16, val uint8) (err error) {
+	// SHA, SHX, SHY, and TAS AND the value with the high byte of the base address plus one.
+	// When indexing crosses a page the high byte of the target is replaced by the stored value.
+	index := g6.Y
+	if g6.CurrentInstruction.Mode == ModeABSX {
+		index = g6.X
+	}
+
+	baseAddress := targetAddress - uint16(index)
+	val &= uint8(baseAddress>>8) + 1
+
+	if g6.pageCrossed {
+		targetAddress = uint16(val)<<8 | targetAddress&0x00FF
+	}
+
+	return g6.writeByte(targetAddress, val)
+}
+
+var undocumentedHandlers = map[Mnemonic]InstructionHandler{
+	// JAM, Lock up the CPU until reset
+	OpJAM: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		g6.halted = true
+		g6.shouldStopPCAutoIncrement = true
+		return nil
+	},
+
+	// SLO, Shift memory left then OR with accumulator
+	OpSLO: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		val, err := modifyTarget(g6, targetAddress, shiftLeft)
+		if err != nil {
+			return err
+		}
+
+		g6.A |= val
+
+		setZeroNegativeFlags(g6, g6.A)
+		return nil
+	},
+
+	// RLA, Rotate memory left then AND with accumulator
+	OpRLA: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		val, err := modifyTarget(g6, targetAddress, rotateLeft)
+		if err != nil {
+			return err
+		}
+
+		g6.A &= val
+
+		setZeroNegativeFlags(g6, g6.A)
+		return nil
+	},
+
+	// SRE, Shift memory right then EOR with accumulator
+	OpSRE: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		val, err := modifyTarget(g6, targetAddress, shiftRight)
+		if err != nil {
+			return err
+		}
+
+		g6.A ^= val
+
+		setZeroNegativeFlags(g6, g6.A)
+		return nil
+	},
+
+	// RRA, Rotate memory right then add to accumulator with carry
+	OpRRA: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		val, err := modifyTarget(g6, targetAddress, rotateRight)
+		if err != nil {
+			return err
+		}
+
+		addWithCarry(g6, val)
+		return nil
+	},
+
+	// SAX, Store A AND X in memory
+	OpSAX: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		return g6.writeByte(*targetAddress, g6.A&g6.X)
+	},
+
+	// LAX, Load A and X with memory
+	OpLAX: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		val, err := g6.readByte(*targetAddress)
+		if err != nil {
+			return err
+		}
+
+		g6.A = val
+		g6.X = val
+
+		setZeroNegativeFlags(g6, val)
+		return nil
+	},
+
+	// LXA, Load A and X with (A OR magic) AND immediate, unstable
+	OpLXA: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		val, err := g6.readByte(*targetAddress)
+		if err != nil {
+			return err
+		}
+
+		g6.A = (g6.A | g6.MagicConstant) & val
+		g6.X = g6.A
+
+		setZeroNegativeFlags(g6, g6.A)
+		return nil
+	},
+
+	// DCP, Decrement memory then compare with accumulator
+	OpDCP: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		val, err := modifyTarget(g6, targetAddress, decrement)
+		if err != nil {
+			return err
+		}
+
+		compare(g6, g6.A, val)
+		return nil
+	},
+
+	// ISC, Increment memory then subtract from accumulator with borrow
+	OpISC: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		val, err := modifyTarget(g6, targetAddress, increment)
+		if err != nil {
+			return err
+		}
+
+		subtractWithCarry(g6, val)
+		return nil
+	},
+
+	// ANC, AND immediate with accumulator, bit 7 is copied to carry
+	OpANC: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		val, err := g6.readByte(*targetAddress)
+		if err != nil {
+			return err
+		}
+
+		g6.A &= val
+
+		setZeroNegativeFlags(g6, g6.A)
+		g6.Stat.Carry = g6.Stat.Negative
+		return nil
+	},
+
+	// ALR, AND immediate with accumulator then shift right
+	OpALR: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		val, err := g6.readByte(*targetAddress)
+		if err != nil {
+			return err
+		}
+
+		g6.A = shiftRight(g6, g6.A&val)
+		return nil
+	},
+
+	// ARR, AND immediate with accumulator then rotate right, flags come from the adder
+	OpARR: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		val, err := g6.readByte(*targetAddress)
+		if err != nil {
+			return err
+		}
+
+		anded := g6.A & val
+		carryIn := g6.Stat.Carry
+		g6.A = rotateRight(g6, anded)
+
+		if !g6.decimalEnabled() {
+			g6.Stat.Carry = g6.A&0x40 != 0
+			g6.Stat.Overflow = (g6.A>>6)&1 != (g6.A>>5)&1
+			return nil
+		}
+
+		// In decimal mode N is the old carry, V is bit 6 changing, and each digit gets BCD fixed up
+		g6.Stat.Negative = carryIn
+		g6.Stat.Overflow = (anded^g6.A)&0x40 != 0
+
+		if (anded&0x0F)+(anded&0x01) > 0x05 {
+			g6.A = g6.A&0xF0 | (g6.A+0x06)&0x0F
+		}
+
+		g6.Stat.Carry = uint16(anded&0xF0)+uint16(anded&0x10) > 0x50
+		if g6.Stat.Carry {
+			g6.A += 0x60
+		}
+
+		return nil
+	},
+
+	// SBX, X = (A AND X) minus immediate without borrow
+	OpSBX: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		val, err := g6.readByte(*targetAddress)
+		if err != nil {
+			return err
+		}
+
+		anded := g6.A & g6.X
+		compare(g6, anded, val)
+		g6.X = anded - val
+		return nil
+	},
+
+	// USBC, Identical to SBC immediate
+	OpUSBC: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		return InstructionHandlers[OpSBC](g6, targetAddress)
+	},
+
+	// XAA, A = (A OR magic) AND X AND immediate, unstable
+	OpXAA: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		val, err := g6.readByte(*targetAddress)
+		if err != nil {
+			return err
+		}
+
+		g6.A = (g6.A | g6.MagicConstant) & g6.X & val
+
+		setZeroNegativeFlags(g6, g6.A)
+		return nil
+	},
+
+	// LAS, Load A, X, and SP with memory AND SP
+	OpLAS: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		val, err := g6.readByte(*targetAddress)
+		if err != nil {
+			return err
+		}
+
+		g6.SP &= val
+		g6.A = g6.SP
+		g6.X = g6.SP
+
+		setZeroNegativeFlags(g6, g6.SP)
+		return nil
+	},
+
+	// TAS, SP = A AND X, then store SP AND high byte of address + 1
+	OpTAS: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		g6.SP = g6.A & g6.X
+		return storeHighByteAnd(g6, *targetAddress, g6.SP)
+	},
+
+	// SHA, Store A AND X AND high byte of address + 1
+	OpSHA: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		return storeHighByteAnd(g6, *targetAddress, g6.A&g6.X)
+	},
+
+	// SHY, Store Y AND high byte of address + 1
+	OpSHY: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		return storeHighByteAnd(g6, *targetAddress, g6.Y)
+	},
+
+	// SHX, Store X AND high byte of address + 1
+	OpSHX: func(g6 *Go6502, targetAddress *uint16) (err error) {
+		return storeHighByteAnd(g6, *targetAddress, g6.X)
+	},
+}
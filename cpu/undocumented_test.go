@@ -0,0 +1,139 @@
+package cpu
+
+import (
+	"github.com/edison-moreland/go6502/testingHelp"
+	"testing"
+)
+
+func TestInstructionSet_Complete(t *testing.T) {
+	// Every opcode decodes on the NMOS 6502
+	for opcode := 0; opcode <= 0xFF; opcode++ {
+		instruction := InstructionSet[opcode]
+		testingHelp.Assert(t, instruction.Mnemonic != OpUnknown, "opcode %#x does not exist", opcode)
+		testingHelp.Equals(t, byte(opcode), instruction.Opcode)
+	}
+
+	for _, instruction := range UndocumentedInstructionSet {
+		testingHelp.Assert(t, InstructionHandlers[instruction.Mnemonic] != nil, "%v has no handler", instruction.Mnemonic)
+	}
+}
+
+type instructionTestData struct {
+	description string
+	setup       func(cpu *Go6502)
+	program     []byte
+	check       func(t *testing.T, cpu *Go6502)
+}
+
+var undocumentedTests = []instructionTestData{
+	{"LAX zeropage", func(cpu *Go6502) {
+		_ = cpu.Mem.WriteByte(0x0010, 0x80)
+	}, []byte{0xa7, 0x10}, func(t *testing.T, cpu *Go6502) {
+		testingHelp.Equals(t, byte(0x80), cpu.A)
+		testingHelp.Equals(t, byte(0x80), cpu.X)
+		testingHelp.Equals(t, Status{Negative: true}, cpu.Stat)
+	}},
+	{"SAX absolute", func(cpu *Go6502) {
+		cpu.A, cpu.X = 0xF0, 0x3C
+	}, []byte{0x8f, 0x00, 0x20}, func(t *testing.T, cpu *Go6502) {
+		val, _ := cpu.Mem.ReadByte(0x2000)
+		testingHelp.Equals(t, byte(0x30), val)
+	}},
+	{"DCP zeropage", func(cpu *Go6502) {
+		cpu.A = 0x41
+		_ = cpu.Mem.WriteByte(0x0010, 0x42)
+	}, []byte{0xc7, 0x10}, func(t *testing.T, cpu *Go6502) {
+		val, _ := cpu.Mem.ReadByte(0x0010)
+		testingHelp.Equals(t, byte(0x41), val)
+		testingHelp.Equals(t, Status{Zero: true, Carry: true}, cpu.Stat)
+	}},
+	{"ISC zeropage", func(cpu *Go6502) {
+		cpu.A = 0x10
+		cpu.Stat.Carry = true
+		_ = cpu.Mem.WriteByte(0x0010, 0x0F)
+	}, []byte{0xe7, 0x10}, func(t *testing.T, cpu *Go6502) {
+		testingHelp.Equals(t, byte(0x00), cpu.A)
+		testingHelp.Equals(t, Status{Zero: true, Carry: true}, cpu.Stat)
+	}},
+	{"SLO zeropage", func(cpu *Go6502) {
+		cpu.A = 0x01
+		_ = cpu.Mem.WriteByte(0x0010, 0x81)
+	}, []byte{0x07, 0x10}, func(t *testing.T, cpu *Go6502) {
+		val, _ := cpu.Mem.ReadByte(0x0010)
+		testingHelp.Equals(t, byte(0x02), val)
+		testingHelp.Equals(t, byte(0x03), cpu.A)
+		testingHelp.Equals(t, Status{Carry: true}, cpu.Stat)
+	}},
+	{"RRA zeropage", func(cpu *Go6502) {
+		cpu.A = 0x10
+		_ = cpu.Mem.WriteByte(0x0010, 0x03)
+	}, []byte{0x67, 0x10}, func(t *testing.T, cpu *Go6502) {
+		// 0x03 rotates to 0x01 with carry set, 0x10 + 0x01 + 1
+		testingHelp.Equals(t, byte(0x12), cpu.A)
+	}},
+	{"ANC", func(cpu *Go6502) {
+		cpu.A = 0xFF
+	}, []byte{0x0b, 0x80}, func(t *testing.T, cpu *Go6502) {
+		testingHelp.Equals(t, Status{Negative: true, Carry: true}, cpu.Stat)
+	}},
+	{"ALR", func(cpu *Go6502) {
+		cpu.A = 0xFF
+	}, []byte{0x4b, 0x03}, func(t *testing.T, cpu *Go6502) {
+		testingHelp.Equals(t, byte(0x01), cpu.A)
+		testingHelp.Equals(t, Status{Carry: true}, cpu.Stat)
+	}},
+	{"ARR", func(cpu *Go6502) {
+		cpu.A = 0xFF
+		cpu.Stat.Carry = true
+	}, []byte{0x6b, 0xC0}, func(t *testing.T, cpu *Go6502) {
+		testingHelp.Equals(t, byte(0xE0), cpu.A)
+		testingHelp.Equals(t, Status{Negative: true, Carry: true}, cpu.Stat)
+	}},
+	{"SBX", func(cpu *Go6502) {
+		cpu.A, cpu.X = 0xFF, 0x0F
+	}, []byte{0xcb, 0x01}, func(t *testing.T, cpu *Go6502) {
+		testingHelp.Equals(t, byte(0x0E), cpu.X)
+		testingHelp.Equals(t, Status{Carry: true}, cpu.Stat)
+	}},
+	{"XAA uses the magic constant", func(cpu *Go6502) {
+		cpu.MagicConstant = 0xEE
+		cpu.A, cpu.X = 0x00, 0xFF
+	}, []byte{0x8b, 0xFF}, func(t *testing.T, cpu *Go6502) {
+		testingHelp.Equals(t, byte(0xEE), cpu.A)
+	}},
+	{"LXA uses the magic constant", func(cpu *Go6502) {
+		cpu.MagicConstant = 0xFF
+	}, []byte{0xab, 0x5A}, func(t *testing.T, cpu *Go6502) {
+		testingHelp.Equals(t, byte(0x5A), cpu.A)
+		testingHelp.Equals(t, byte(0x5A), cpu.X)
+	}},
+	{"SHX page crossing corrupts the target", func(cpu *Go6502) {
+		cpu.X, cpu.Y = 0x11, 0x01
+	}, []byte{0x9e, 0xFF, 0x20}, func(t *testing.T, cpu *Go6502) {
+		// 0x11 AND 0x21 replaces the high byte of 0x2100
+		val, _ := cpu.Mem.ReadByte(0x0100)
+		testingHelp.Equals(t, byte(0x01), val)
+	}},
+}
+
+func TestUndocumentedInstructions(t *testing.T) {
+	for _, testData := range undocumentedTests {
+		t.Run(testData.description, func(t *testing.T) {
+			cpu := new(Go6502)
+			testData.setup(cpu)
+
+			runInstruction(t, cpu, 0x1000, testData.program...)
+			testData.check(t, cpu)
+		})
+	}
+}
+
+func TestJAM_HaltsCPU(t *testing.T) {
+	cpu := new(Go6502)
+	_ = cpu.Mem.WriteByte(0x1000, 0xea) // NOP
+	_ = cpu.Mem.WriteByte(0x1001, 0x02) // JAM
+
+	err := cpu.StartEmulationAtAddress(0x1000)
+	testingHelp.Assert(t, err != nil, "JAM should stop emulation")
+	testingHelp.Equals(t, uint16(0x1001), cpu.PC)
+}
@@ -0,0 +1,73 @@
+package cpu
+
+// Variant is a member of the 6502 family
+type Variant byte
+
+const (
+	// NMOS6502 is the original MOS 6502, undocumented opcodes and all
+	NMOS6502 Variant = iota
+	// MOS6510 is the NMOS 6502 used in the C64, with an I/O port at $0000/$0001
+	MOS6510
+	// RP2A03 is the NES CPU, an NMOS 6502 with decimal mode disconnected
+	RP2A03
+	// WDC65C02 is the CMOS 65C02, including the Rockwell bit instructions
+	WDC65C02
+)
+
+// variantInfo describes everything that changes between variants
+type variantInfo struct {
+	name           string
+	instructionSet *InstructionTable
+	decimalMode    DecimalMode
+
+	// CMOS parts fix the JMP indirect bug and clear decimal mode on interrupt
+	cmos bool
+	// The 6510 has an I/O port at $0000/$0001
+	ioPort bool
+}
+
+var variants = [...]variantInfo{
+	NMOS6502: {"6502", &InstructionSet, DecimalNMOS, false, false},
+	MOS6510:  {"6510", &InstructionSet, DecimalNMOS, false, true},
+	RP2A03:   {"2A03", &InstructionSet, DecimalDisabled, false, false},
+	WDC65C02: {"65C02", &CMOSInstructionSet, DecimalCMOS, true, false},
+}
+
+func (v Variant) String() string {
+	if int(v) < len(variants) {
+		return variants[v].name
+	}
+
+	return "Unknown"
+}
+
+func (g6 *Go6502) Variant() Variant {
+	return g6.variant
+}
+
+func (g6 *Go6502) SetVariant(variant Variant) {
+	// Switches the instruction set and any behaviour that goes along with it
+	if variant != g6.variant {
+		g6.clearHistory()
+	}
+	g6.variant = variant
+	g6.DecimalMode = variants[variant].decimalMode
+
+	g6.port = nil
+	if variants[variant].ioPort {
+		g6.port = NewIOPort()
+	}
+}
+
+func (g6 *Go6502) Port() *IOPort {
+	// On-chip I/O port, nil unless the variant has one
+	return g6.port
+}
+
+func (g6 *Go6502) isCMOS() bool {
+	return variants[g6.variant].cmos
+}
+
+func (g6 *Go6502) instructionSet() *InstructionTable {
+	return variants[g6.variant].instructionSet
+}
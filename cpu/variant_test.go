@@ -0,0 +1,41 @@
+package cpu
+
+import (
+	"github.com/edison-moreland/go6502/testingHelp"
+	"testing"
+)
+
+func TestNew_WithVariant(t *testing.T) {
+	// Different variants can run side by side
+	for _, variant := range []Variant{NMOS6502, MOS6510, RP2A03, WDC65C02} {
+		cpu := New(WithVariant(variant))
+		testingHelp.Equals(t, variant, cpu.Variant())
+	}
+
+	testingHelp.Equals(t, NMOS6502, New().Variant())
+}
+
+func TestVariant_InstructionSet(t *testing.T) {
+	// 0x07 is SLO on NMOS parts and RMB0 on the 65C02
+	nmos := New(WithVariant(MOS6510))
+	cmos := New(WithVariant(WDC65C02))
+
+	testingHelp.Equals(t, OpSLO, nmos.instructionSet()[0x07].Mnemonic)
+	testingHelp.Equals(t, OpRMB0, cmos.instructionSet()[0x07].Mnemonic)
+}
+
+func TestVariant_2A03HasNoDecimalMode(t *testing.T) {
+	cpu := New(WithVariant(RP2A03))
+	cpu.A = 0x09
+	cpu.Stat.Decimal = true
+
+	runInstruction(t, cpu, 0x1000, 0x69, 0x01)
+	testingHelp.Equals(t, byte(0x0A), cpu.A)
+
+	cpu = New(WithVariant(MOS6510))
+	cpu.A = 0x09
+	cpu.Stat.Decimal = true
+
+	runInstruction(t, cpu, 0x1000, 0x69, 0x01)
+	testingHelp.Equals(t, byte(0x10), cpu.A)
+}
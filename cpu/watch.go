@@ -0,0 +1,56 @@
+package cpu
+
+import (
+	"github.com/edison-moreland/go6502/memory"
+	"github.com/pkg/errors"
+)
+
+/*
+Watchpoints
+-----------
+Every read and write the CPU makes is checked against the watchpoints, and each
+opcode fetch is also checked as an execute. The instruction doing the access
+always finishes, if a watchpoint asked to stop the step then returns a
+WatchpointError for the first one that did. Running again carries on from the
+next instruction.
+*/
+
+func (g6 *Go6502) Watch(wp *memory.Watchpoint) error {
+	if g6.watch == nil {
+		g6.watch = new(memory.Watchpoints)
+	}
+
+	return g6.watch.Add(wp)
+}
+
+func (g6 *Go6502) Unwatch(wp *memory.Watchpoint) error {
+	if g6.watch == nil {
+		return errors.Errorf("Watchpoint %#04x-%#04x isn't set", wp.Start, wp.End)
+	}
+
+	// Drop the checks entirely once nothing is watched
+	if err := g6.watch.Remove(wp); err != nil {
+		return err
+	}
+	if g6.watch.Len() == 0 {
+		g6.watch = nil
+	}
+	return nil
+}
+
+func (g6 *Go6502) checkWatch(access memory.Access, address uint16, old, new byte) {
+	if hit := g6.watch.Check(access, address, g6.PC, old, new); hit != nil && g6.watchHit == nil {
+		g6.watchHit = hit
+	}
+}
+
+func (g6 *Go6502) watchStop() error {
+	// Hands over the watchpoint that asked to stop during this step, if any
+	if g6.watchHit == nil {
+		return nil
+	}
+
+	hit := *g6.watchHit
+	g6.watchHit = nil
+	return &WatchpointError{Hit: hit, Registers: g6.Registers()}
+}
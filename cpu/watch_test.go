@@ -0,0 +1,60 @@
+package cpu
+
+import (
+	"github.com/edison-moreland/go6502/memory"
+	"github.com/edison-moreland/go6502/testingHelp"
+	"github.com/pkg/errors"
+	"testing"
+)
+
+func TestGo6502_WatchWrite(t *testing.T) {
+	cpu := New()
+	cpu.Mem.Mem[0x0010] = 0x07
+	testingHelp.NotNil(t, cpu.Watch(&memory.Watchpoint{
+		Start: 0x0010, End: 0x0010, Access: memory.AccessWrite, Stop: true,
+		// INC writes the old value back before the new one
+		Condition: func(old, new byte) bool { return old != new },
+	}))
+
+	program := []byte{0xea, 0xe6, 0x10, 0xea} // NOP, INC $10, NOP
+	for i, programByte := range program {
+		testingHelp.NotNil(t, cpu.Mem.WriteByte(0x1000+uint16(i), programByte))
+	}
+	cpu.PC = 0x1000
+
+	err := cpu.RunFor(3, UnitInstructions)
+
+	watch, ok := errors.Cause(err).(*WatchpointError)
+	testingHelp.Assert(t, ok, "expected a WatchpointError, got %v", err)
+	testingHelp.Equals(t, memory.AccessWrite, watch.Hit.Access)
+	testingHelp.Equals(t, uint16(0x1001), watch.Hit.PC)
+	testingHelp.Equals(t, byte(0x07), watch.Hit.Old)
+	testingHelp.Equals(t, byte(0x08), watch.Hit.New)
+
+	// The instruction finished, running again carries on after it
+	testingHelp.Equals(t, uint16(0x1003), cpu.PC)
+	testingHelp.NotNil(t, cpu.RunFor(1, UnitInstructions))
+	testingHelp.Equals(t, uint16(0x1004), cpu.PC)
+}
+
+func TestGo6502_WatchExecute(t *testing.T) {
+	cpu := New()
+	var hits []memory.Hit
+	wp := &memory.Watchpoint{
+		Start: 0x1000, End: 0x10FF, Access: memory.AccessExecute | memory.AccessRead,
+		Condition: func(old, new byte) bool { return new == 0xa5 },
+		Handler:   func(hit memory.Hit) { hits = append(hits, hit) },
+	}
+	testingHelp.NotNil(t, cpu.Watch(wp))
+
+	// LDA $10 is executed, and its opcode read, from $1001
+	runProgram(t, cpu, 0x1000, 2, 0xea, 0xa5, 0x10)
+	testingHelp.Equals(t, []memory.Hit{
+		{Watchpoint: wp, Access: memory.AccessRead, Address: 0x1001, PC: 0x1001, Old: 0xa5, New: 0xa5},
+		{Watchpoint: wp, Access: memory.AccessExecute, Address: 0x1001, PC: 0x1001, Old: 0xa5, New: 0xa5},
+	}, hits)
+
+	testingHelp.NotNil(t, cpu.Unwatch(wp))
+	testingHelp.Assert(t, cpu.Unwatch(wp) != nil, "expected unwatching twice to fail")
+	testingHelp.Assert(t, cpu.watch == nil, "expected unwatching nothing to leave the checks off")
+}
@@ -2,4 +2,4 @@ module github.com/edison-moreland/go6502
 
 go 1.12
 
-require github.com/pkg/errors v0.8.1
+require github.com/pkg/errors v0.9.1
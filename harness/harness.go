@@ -0,0 +1,144 @@
+package harness
+
+import (
+	"fmt"
+	"github.com/edison-moreland/go6502/cpu"
+	"github.com/pkg/errors"
+	"io"
+	"strings"
+)
+
+/*
+Functional test ROMs
+--------------------
+Test ROMs like Klaus Dormann's 6502_functional_test don't have any way to
+report a result, instead they "trap" by jumping or branching to themselves
+forever. Where they get stuck says whether the test passed: one address means
+success, anywhere else is the test that failed.
+
+https://github.com/Klaus2m5/6502_65C02_functional_tests
+*/
+
+// Config describes how to run a test ROM
+type Config struct {
+	// Image is loaded into memory at LoadAddress
+	Image       io.Reader
+	LoadAddress uint16
+	// StartPC is where execution starts, no reset happens
+	StartPC uint16
+	// SuccessAddress is where the ROM traps once every test has passed
+	SuccessAddress uint16
+	// Variant to run the ROM on, the 65C02 tests need WDC65C02
+	Variant cpu.Variant
+	// MaxInstructions gives up on a ROM that never traps, 0 means no limit
+	MaxInstructions uint64
+	// Setup is optional, it's called after loading to attach devices or schedule interrupts
+	Setup func(g6 *cpu.Go6502)
+}
+
+// Result is where a test ROM ended up
+type Result struct {
+	Passed bool
+	// TrapPC is the address of the instruction that trapped
+	TrapPC       uint16
+	Instructions uint64
+	Cycles       uint64
+	// Dump of the CPU state when the ROM stopped
+	Dump string
+}
+
+func Load(config Config) (g6 *cpu.Go6502, err error) {
+	// Creates a CPU with the ROM loaded, ready to run from StartPC
+	g6 = cpu.New(cpu.WithVariant(config.Variant))
+	if _, err = g6.Mem.LoadRaw(config.Image, config.LoadAddress); err != nil {
+		return nil, errors.Wrap(err, "Error loading test ROM")
+	}
+	g6.PC = config.StartPC
+	g6.SP = 0xFD
+
+	return g6, nil
+}
+
+func Run(config Config) (result Result, err error) {
+	// Runs a test ROM until it traps, err is set unless it trapped at SuccessAddress
+	g6, err := Load(config)
+	if err != nil {
+		return result, err
+	}
+	if config.Setup != nil {
+		config.Setup(g6)
+	}
+
+	trapped := false
+	lastPC := g6.PC
+	err = g6.RunUntil(func(g6 *cpu.Go6502) bool {
+		// A trap is a jump or branch to itself, WAI also leaves PC alone but isn't one
+		trapped = !g6.Waiting() && g6.PC == lastPC && selfJump(g6.CurrentInstruction.Mnemonic)
+		lastPC = g6.PC
+		return trapped || (config.MaxInstructions != 0 && g6.Instructions >= config.MaxInstructions)
+	})
+
+	result = Result{
+		TrapPC:       g6.PC,
+		Instructions: g6.Instructions,
+		Cycles:       g6.Cycles,
+		Dump:         Dump(g6),
+	}
+
+	switch {
+	case err != nil:
+		return result, errors.Wrapf(err, "Test ROM stopped with an error\n%v", result.Dump)
+	case !trapped:
+		return result, errors.Errorf("Test ROM didn't trap within %v instructions\n%v", config.MaxInstructions, result.Dump)
+	case g6.PC != config.SuccessAddress:
+		return result, errors.Errorf("Test ROM failed, trapped at %#04x instead of %#04x\n%v", g6.PC, config.SuccessAddress, result.Dump)
+	}
+
+	result.Passed = true
+	return result, nil
+}
+
+func selfJump(mnemonic cpu.Mnemonic) bool {
+	// Instructions that can leave PC on their own address
+	switch mnemonic {
+	case cpu.OpJMP, cpu.OpBRA,
+		cpu.OpBCC, cpu.OpBCS, cpu.OpBEQ, cpu.OpBMI, cpu.OpBNE, cpu.OpBPL, cpu.OpBVC, cpu.OpBVS:
+		return true
+	}
+
+	// Rockwell branch on bit instructions
+	return mnemonic >= cpu.OpBBR0 && mnemonic <= cpu.OpBBS7
+}
+
+func Dump(g6 *cpu.Go6502) string {
+	// Registers, the current instruction, zeropage and the stack page
+	var b strings.Builder
+	fmt.Fprintf(&b, "%v\n", g6.TraceLine())
+	fmt.Fprintf(&b, "N:%v V:%v D:%v I:%v Z:%v C:%v\n",
+		flag(g6.Stat.Negative), flag(g6.Stat.Overflow), flag(g6.Stat.Decimal),
+		flag(g6.Stat.InterruptDisable), flag(g6.Stat.Zero), flag(g6.Stat.Carry))
+
+	b.WriteString("Zeropage:\n")
+	hexDump(&b, g6.Mem.Mem[0x0000:0x0100], 0x0000)
+	b.WriteString("Stack:\n")
+	hexDump(&b, g6.Mem.Mem[0x0100:0x0200], 0x0100)
+
+	return b.String()
+}
+
+func flag(set bool) int {
+	if set {
+		return 1
+	}
+	return 0
+}
+
+func hexDump(b *strings.Builder, data []byte, address uint16) {
+	for row := 0; row < len(data); row += 16 {
+		fmt.Fprintf(b, "%04X:", int(address)+row)
+		for _, value := range data[row : row+16] {
+			fmt.Fprintf(b, " %02X", value)
+		}
+		b.WriteString("\n")
+	}
+}
@@ -0,0 +1,150 @@
+package harness
+
+import (
+	"bytes"
+	"github.com/edison-moreland/go6502/cpu"
+	"github.com/edison-moreland/go6502/testingHelp"
+	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Counts X up to 5, then traps at $0209 if it got there or $020B if it didn't
+var countingROM = []byte{
+	0xa2, 0x00, // $0200 LDX #$00
+	0xe8,       // $0202 INX
+	0xe0, 0x05, // $0203 CPX #$05
+	0xd0, 0xfb, // $0205 BNE $0202
+	0xf0, 0x00, // $0207 BEQ $0209
+	0xf0, 0xfe, // $0209 BEQ $0209, success
+	0x4c, 0x0b, 0x02, // $020B JMP $020B, failure
+}
+
+func TestRun_Pass(t *testing.T) {
+	result, err := Run(Config{
+		Image:          bytes.NewReader(countingROM),
+		LoadAddress:    0x0200,
+		StartPC:        0x0200,
+		SuccessAddress: 0x0209,
+	})
+	testingHelp.NotNil(t, err)
+	testingHelp.Equals(t, true, result.Passed)
+	testingHelp.Equals(t, uint16(0x0209), result.TrapPC)
+}
+
+func TestRun_Fail(t *testing.T) {
+	result, err := Run(Config{
+		Image:          bytes.NewReader(countingROM),
+		LoadAddress:    0x0200,
+		StartPC:        0x020B,
+		SuccessAddress: 0x0209,
+	})
+	testingHelp.Assert(t, err != nil, "expected trap at the wrong address to fail")
+	testingHelp.Equals(t, false, result.Passed)
+	testingHelp.Equals(t, uint16(0x020B), result.TrapPC)
+
+	// Failures come with the CPU state
+	testingHelp.Assert(t, strings.Contains(err.Error(), "020B  4C 0B 02  JMP $020B"), "expected dump in error, got %v", err)
+	testingHelp.Assert(t, strings.Contains(err.Error(), "Zeropage:"), "expected zeropage dump in error, got %v", err)
+}
+
+func TestRun_NeverTraps(t *testing.T) {
+	// INX, JMP $0200
+	_, err := Run(Config{
+		Image:           bytes.NewReader([]byte{0xe8, 0x4c, 0x00, 0x02}),
+		LoadAddress:     0x0200,
+		StartPC:         0x0200,
+		MaxInstructions: 1000,
+	})
+	testingHelp.Assert(t, err != nil, "expected a ROM that never traps to fail")
+}
+
+func TestRun_Halted(t *testing.T) {
+	_, err := Run(Config{
+		Image:       bytes.NewReader([]byte{0x02}), // JAM
+		LoadAddress: 0x0200,
+		StartPC:     0x0200,
+	})
+	testingHelp.Assert(t, err != nil, "expected JAM to fail")
+}
+
+func TestRun_HaltedSTP(t *testing.T) {
+	_, err := Run(Config{
+		Image:       bytes.NewReader([]byte{0xdb}), // STP
+		LoadAddress: 0x0200,
+		StartPC:     0x0200,
+		Variant:     cpu.WDC65C02,
+	})
+
+	halted, ok := errors.Cause(err).(*cpu.CPUHaltedError)
+	testingHelp.Assert(t, ok, "expected STP to fail with a CPUHaltedError, got %v", err)
+	testingHelp.Equals(t, uint16(0x0200), halted.PC)
+}
+
+func TestRun_WAI(t *testing.T) {
+	// Waiting leaves PC alone, it isn't a trap
+	rom := []byte{
+		0x58,       // $0200 CLI
+		0xcb,       // $0201 WAI
+		0xea,       // $0202 NOP
+		0x80, 0xfe, // $0203 BRA $0203, success
+	}
+	rom = append(rom, make([]byte, 0x0210-0x0200-len(rom))...)
+	rom = append(rom, 0x40) // $0210 RTI
+
+	result, err := Run(Config{
+		Image:          bytes.NewReader(rom),
+		LoadAddress:    0x0200,
+		StartPC:        0x0200,
+		SuccessAddress: 0x0203,
+		Variant:        cpu.WDC65C02,
+		Setup: func(g6 *cpu.Go6502) {
+			_ = g6.Mem.WriteWord(0xFFFE, 0x0210)
+			g6.ScheduleIn(100, func(cycle uint64) { g6.AssertIRQ(0) })
+			g6.ScheduleIn(105, func(cycle uint64) { g6.ReleaseIRQ(0) })
+		},
+	})
+	testingHelp.NotNil(t, err)
+	testingHelp.Equals(t, uint16(0x0203), result.TrapPC)
+	testingHelp.Assert(t, result.Cycles > 100, "expected the ROM to wait for the IRQ, ran %v cycles", result.Cycles)
+}
+
+func TestLoad_TooBig(t *testing.T) {
+	_, err := Load(Config{Image: bytes.NewReader(make([]byte, 0x100)), LoadAddress: 0xFF80})
+	testingHelp.Assert(t, err != nil, "expected image past the end of memory to fail")
+}
+
+// Klaus Dormann's test ROMs aren't distributed with the repo, drop the binaries in testdata to run them
+// https://github.com/Klaus2m5/6502_65C02_functional_tests
+func TestFunctionalTestROMs(t *testing.T) {
+	roms := []struct {
+		file           string
+		variant        cpu.Variant
+		successAddress uint16
+	}{
+		{"6502_functional_test.bin", cpu.NMOS6502, 0x3469},
+		{"65C02_extended_opcodes_test.bin", cpu.WDC65C02, 0x24f1},
+	}
+
+	for _, rom := range roms {
+		image, err := os.Open(filepath.Join("testdata", rom.file))
+		if os.IsNotExist(err) {
+			t.Logf("Skipping %v, not found in testdata", rom.file)
+			continue
+		}
+		testingHelp.NotNil(t, err)
+
+		result, err := Run(Config{
+			Image:          image,
+			LoadAddress:    0x0000,
+			StartPC:        0x0400,
+			SuccessAddress: rom.successAddress,
+			Variant:        rom.variant,
+		})
+		image.Close()
+		testingHelp.NotNil(t, err)
+		t.Logf("%v passed after %v instructions", rom.file, result.Instructions)
+	}
+}
@@ -0,0 +1,150 @@
+package memory
+
+import "github.com/pkg/errors"
+
+/*
+Bank switching
+--------------
+BankedBus splits the address space into windows, each window shows either the
+RAM underneath or one of the banks selected into it. Banks are plain byte
+slices, so one ROM image can be selected into several windows, and a bank
+bigger than its window can be paged through by selecting it at an offset.
+
+Writes to a ROM bank go through to the RAM underneath, like on the C64. Reads
+always see whatever is selected. Switching banks at runtime is up to whatever
+owns the bus, usually a Device for a bank register or the 6510's I/O port.
+*/
+
+// Bank is a RAM or ROM image that can be selected into a window
+type Bank struct {
+	Name string
+	Data []byte
+	// ROM banks can't be written, writes go to the RAM underneath instead
+	ROM bool
+}
+
+func NewROM(name string, data []byte) *Bank {
+	return &Bank{Name: name, Data: data, ROM: true}
+}
+
+func NewRAM(name string, size int) *Bank {
+	return &Bank{Name: name, Data: make([]byte, size)}
+}
+
+// Window is a range of addresses that banks can be selected into
+type Window struct {
+	start, end uint16
+
+	// nil when RAM is showing through
+	bank   *Bank
+	offset int
+}
+
+func (w *Window) size() int {
+	return int(w.end) - int(w.start) + 1
+}
+
+func (w *Window) Start() uint16 {
+	return w.start
+}
+
+func (w *Window) End() uint16 {
+	return w.end
+}
+
+func (w *Window) Selected() *Bank {
+	// Bank showing in the window, nil if it's RAM
+	return w.bank
+}
+
+func (w *Window) Select(bank *Bank) (err error) {
+	// Shows bank in the window, nil shows the RAM underneath
+	return w.SelectAt(bank, 0)
+}
+
+func (w *Window) SelectAt(bank *Bank, offset int) (err error) {
+	// Shows bank in the window starting from offset, for banks bigger than the window
+	if bank != nil && (offset < 0 || offset+w.size() > len(bank.Data)) {
+		return errors.Errorf("Bank %v is too small for window %#04x-%#04x at offset %#x", bank.Name, w.start, w.end, offset)
+	}
+
+	w.bank, w.offset = bank, offset
+	return nil
+}
+
+func (w *Window) contains(address uint16) bool {
+	return address >= w.start && address <= w.end
+}
+
+func (w *Window) index(address uint16) int {
+	return w.offset + int(address-w.start)
+}
+
+type BankedBus struct {
+	// RAM is underneath every window
+	RAM Bus
+
+	windows []*Window
+	// Window covering each page, windows can share a page but not addresses
+	pages [0x100][]*Window
+}
+
+func NewBankedBus(ram Bus) *BankedBus {
+	return &BankedBus{RAM: ram}
+}
+
+func (b *BankedBus) AddWindow(start, end uint16) (window *Window, err error) {
+	// Creates a window over start to end inclusive, it starts out showing RAM
+	if end < start {
+		return nil, errors.Errorf("Window %#04x-%#04x ends before it starts", start, end)
+	}
+
+	for _, existing := range b.windows {
+		if start <= existing.end && existing.start <= end {
+			return nil, errors.Errorf("Window %#04x-%#04x overlaps %#04x-%#04x", start, end, existing.start, existing.end)
+		}
+	}
+
+	window = &Window{start: start, end: end}
+	b.windows = append(b.windows, window)
+	for page := int(start >> 8); page <= int(end>>8); page++ {
+		b.pages[page] = append(b.pages[page], window)
+	}
+
+	return window, nil
+}
+
+func (b *BankedBus) selected(address uint16) (*Window, *Bank) {
+	for _, w := range b.pages[address>>8] {
+		if w.contains(address) {
+			return w, w.bank
+		}
+	}
+
+	return nil, nil
+}
+
+func (b *BankedBus) ReadByte(address uint16) (value byte, err error) {
+	if w, bank := b.selected(address); bank != nil {
+		return bank.Data[w.index(address)], nil
+	}
+
+	return b.RAM.ReadByte(address)
+}
+
+func (b *BankedBus) WriteByte(address uint16, value byte) (err error) {
+	if w, bank := b.selected(address); bank != nil && !bank.ROM {
+		bank.Data[w.index(address)] = value
+		return nil
+	}
+
+	return b.RAM.WriteByte(address, value)
+}
+
+func (b *BankedBus) PeekByte(address uint16) byte {
+	if w, bank := b.selected(address); bank != nil {
+		return bank.Data[w.index(address)]
+	}
+
+	return Peek(b.RAM, address)
+}
@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"github.com/edison-moreland/go6502/testingHelp"
+	"testing"
+)
+
+func TestBankedBus_ROMWritesFallThrough(t *testing.T) {
+	ram := new(Memory)
+	bus := NewBankedBus(ram)
+	window, err := bus.AddWindow(0xE000, 0xFFFF)
+	testingHelp.NotNil(t, err)
+
+	rom := NewROM("kernal", make([]byte, 0x2000))
+	rom.Data[0x1FFC] = 0xE2
+	testingHelp.NotNil(t, window.Select(rom))
+
+	// Reads see the ROM, writes land in RAM underneath
+	testingHelp.NotNil(t, bus.WriteByte(0xFFFC, 0x34))
+	value, err := bus.ReadByte(0xFFFC)
+	testingHelp.NotNil(t, err)
+	testingHelp.Equals(t, byte(0xE2), value)
+	testingHelp.Equals(t, byte(0x34), ram.Mem[0xFFFC])
+
+	// Banking the ROM out shows what was written
+	testingHelp.NotNil(t, window.Select(nil))
+	value, err = bus.ReadByte(0xFFFC)
+	testingHelp.NotNil(t, err)
+	testingHelp.Equals(t, byte(0x34), value)
+}
+
+func TestBankedBus_PagedRAM(t *testing.T) {
+	ram := new(Memory)
+	bus := NewBankedBus(ram)
+	window, err := bus.AddWindow(0x8000, 0x8FFF)
+	testingHelp.NotNil(t, err)
+
+	// 4 pages of 4K, selected through the same window
+	expansion := NewRAM("expansion", 0x4000)
+	for page := 0; page < 4; page++ {
+		testingHelp.NotNil(t, window.SelectAt(expansion, page*0x1000))
+		testingHelp.NotNil(t, bus.WriteByte(0x8010, byte(page)))
+	}
+
+	for page := 0; page < 4; page++ {
+		testingHelp.NotNil(t, window.SelectAt(expansion, page*0x1000))
+		testingHelp.Equals(t, byte(page), Peek(bus, 0x8010))
+	}
+	testingHelp.Equals(t, byte(0x00), ram.Mem[0x8010])
+
+	err = window.SelectAt(expansion, 0x3800)
+	testingHelp.Assert(t, err != nil, "expected selecting past the end of the bank to fail")
+}
+
+func TestBankedBus_AddWindow(t *testing.T) {
+	bus := NewBankedBus(new(Memory))
+	_, err := bus.AddWindow(0xA000, 0xBFFF)
+	testingHelp.NotNil(t, err)
+
+	_, err = bus.AddWindow(0xB000, 0xCFFF)
+	testingHelp.Assert(t, err != nil, "expected overlapping windows to fail")
+
+	_, err = bus.AddWindow(0xC000, 0xBFFF)
+	testingHelp.Assert(t, err != nil, "expected a backwards window to fail")
+}
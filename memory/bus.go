@@ -0,0 +1,159 @@
+package memory
+
+import "github.com/pkg/errors"
+
+/*
+Bus
+---
+The CPU reads and writes everything through a Bus. Memory is a Bus on its own,
+flat RAM with nothing else attached. MappedBus puts devices in front of RAM:
+each device claims a range of addresses, anything it doesn't claim goes
+through to RAM.
+
+Devices are looked up by page, so unclaimed pages cost a single slice check.
+*/
+
+// Bus is anything the CPU can read and write through
+type Bus interface {
+	ReadByte(address uint16) (byte, error)
+	WriteByte(address uint16, value byte) error
+}
+
+// Peeker is a Bus that can be read without side effects, for debuggers and tracers
+type Peeker interface {
+	PeekByte(address uint16) byte
+}
+
+// Device handles accesses to the addresses it claims
+type Device struct {
+	// Read is called for reads, if nil reads go through to RAM
+	Read func(address uint16) byte
+	// Write is called for writes, if nil writes go through to RAM
+	Write func(address uint16, value byte)
+	// Peek reads without side effects, if nil Read is used instead
+	Peek func(address uint16) byte
+}
+
+type region struct {
+	start, end uint16
+	device     Device
+}
+
+func (r *region) contains(address uint16) bool {
+	return address >= r.start && address <= r.end
+}
+
+type MappedBus struct {
+	// RAM handles every access no device claims
+	RAM Bus
+
+	regions []*region
+	// Regions touching each page
+	pages [0x100][]*region
+}
+
+func NewMappedBus(ram Bus) *MappedBus {
+	return &MappedBus{RAM: ram}
+}
+
+func (b *MappedBus) Map(start, end uint16, device Device) (err error) {
+	// Claims start to end inclusive for device, ranges can't overlap
+	if end < start {
+		return errors.Errorf("Device range %#04x-%#04x ends before it starts", start, end)
+	}
+
+	for _, existing := range b.regions {
+		if start <= existing.end && existing.start <= end {
+			return errors.Errorf("Device range %#04x-%#04x overlaps %#04x-%#04x", start, end, existing.start, existing.end)
+		}
+	}
+
+	r := &region{start, end, device}
+	b.regions = append(b.regions, r)
+	for page := int(start >> 8); page <= int(end>>8); page++ {
+		b.pages[page] = append(b.pages[page], r)
+	}
+
+	return nil
+}
+
+func (b *MappedBus) Unmap(start uint16) (err error) {
+	// Removes the device whose range starts at start
+	for i, r := range b.regions {
+		if r.start != start {
+			continue
+		}
+
+		b.regions = append(b.regions[:i], b.regions[i+1:]...)
+		for page := int(r.start >> 8); page <= int(r.end>>8); page++ {
+			b.pages[page] = removeRegion(b.pages[page], r)
+		}
+		return nil
+	}
+
+	return errors.Errorf("No device mapped at %#04x", start)
+}
+
+func removeRegion(regions []*region, remove *region) []*region {
+	kept := regions[:0]
+	for _, r := range regions {
+		if r != remove {
+			kept = append(kept, r)
+		}
+	}
+
+	if len(kept) == 0 {
+		return nil
+	}
+	return kept
+}
+
+func (b *MappedBus) device(address uint16) *Device {
+	for _, r := range b.pages[address>>8] {
+		if r.contains(address) {
+			return &r.device
+		}
+	}
+
+	return nil
+}
+
+func (b *MappedBus) ReadByte(address uint16) (value byte, err error) {
+	if device := b.device(address); device != nil && device.Read != nil {
+		return device.Read(address), nil
+	}
+
+	return b.RAM.ReadByte(address)
+}
+
+func (b *MappedBus) WriteByte(address uint16, value byte) (err error) {
+	if device := b.device(address); device != nil && device.Write != nil {
+		device.Write(address, value)
+		return nil
+	}
+
+	return b.RAM.WriteByte(address, value)
+}
+
+func (b *MappedBus) PeekByte(address uint16) byte {
+	if device := b.device(address); device != nil {
+		if device.Peek != nil {
+			return device.Peek(address)
+		}
+		if device.Read != nil {
+			return device.Read(address)
+		}
+	}
+
+	return Peek(b.RAM, address)
+}
+
+func Peek(bus Bus, address uint16) byte {
+	// Reads without side effects if bus can, otherwise does a normal read
+	if peeker, ok := bus.(Peeker); ok {
+		return peeker.PeekByte(address)
+	}
+
+	value, _ := bus.ReadByte(address)
+	return value
+}
@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"github.com/edison-moreland/go6502/testingHelp"
+	"testing"
+)
+
+// Counts accesses so side effects can be checked
+type testDevice struct {
+	registers     [0x10]byte
+	reads, writes int
+}
+
+func (td *testDevice) device() Device {
+	return Device{
+		Read: func(address uint16) byte {
+			td.reads++
+			return td.registers[address&0x0F]
+		},
+		Write: func(address uint16, value byte) {
+			td.writes++
+			td.registers[address&0x0F] = value
+		},
+	}
+}
+
+func TestMappedBus_Map(t *testing.T) {
+	ram := new(Memory)
+	bus := NewMappedBus(ram)
+	td := &testDevice{}
+	testingHelp.NotNil(t, bus.Map(0xD000, 0xD00F, td.device()))
+
+	testingHelp.NotNil(t, bus.WriteByte(0xD005, 0x42))
+	testingHelp.NotNil(t, bus.WriteByte(0xD010, 0x24))
+
+	value, err := bus.ReadByte(0xD005)
+	testingHelp.NotNil(t, err)
+	testingHelp.Equals(t, byte(0x42), value)
+	testingHelp.Equals(t, 1, td.writes)
+	testingHelp.Equals(t, 1, td.reads)
+
+	// Only the device saw the first write, RAM got the second
+	testingHelp.Equals(t, byte(0x00), ram.Mem[0xD005])
+	testingHelp.Equals(t, byte(0x24), ram.Mem[0xD010])
+
+	testingHelp.NotNil(t, bus.Unmap(0xD000))
+	value, err = bus.ReadByte(0xD005)
+	testingHelp.NotNil(t, err)
+	testingHelp.Equals(t, byte(0x00), value)
+}
+
+func TestMappedBus_MapOverlap(t *testing.T) {
+	bus := NewMappedBus(new(Memory))
+	testingHelp.NotNil(t, bus.Map(0xD000, 0xD0FF, Device{}))
+
+	err := bus.Map(0xD0F0, 0xD1FF, Device{})
+	testingHelp.Assert(t, err != nil, "expected overlapping ranges to fail")
+
+	err = bus.Map(0xD200, 0xD1FF, Device{})
+	testingHelp.Assert(t, err != nil, "expected a backwards range to fail")
+
+	err = bus.Unmap(0xD100)
+	testingHelp.Assert(t, err != nil, "expected unmapping nothing to fail")
+
+	// Touching but not overlapping is fine
+	testingHelp.NotNil(t, bus.Map(0xD100, 0xD1FF, Device{}))
+}
+
+func TestMappedBus_FallThrough(t *testing.T) {
+	// A device without a Write sends writes through to RAM
+	ram := new(Memory)
+	bus := NewMappedBus(ram)
+	testingHelp.NotNil(t, bus.Map(0xA000, 0xBFFF, Device{
+		Read: func(address uint16) byte { return 0xEA },
+	}))
+
+	testingHelp.NotNil(t, bus.WriteByte(0xA000, 0x11))
+	testingHelp.Equals(t, byte(0x11), ram.Mem[0xA000])
+
+	value, err := bus.ReadByte(0xA000)
+	testingHelp.NotNil(t, err)
+	testingHelp.Equals(t, byte(0xEA), value)
+}
+
+func TestMappedBus_PeekByte(t *testing.T) {
+	ram := new(Memory)
+	ram.Mem[0x1234] = 0x56
+	bus := NewMappedBus(ram)
+	td := &testDevice{}
+	td.registers[0x03] = 0x78
+
+	device := td.device()
+	device.Peek = func(address uint16) byte { return td.registers[address&0x0F] }
+	testingHelp.NotNil(t, bus.Map(0xDC00, 0xDC0F, device))
+
+	testingHelp.Equals(t, byte(0x78), Peek(bus, 0xDC03))
+	testingHelp.Equals(t, byte(0x56), Peek(bus, 0x1234))
+	testingHelp.Equals(t, 0, td.reads)
+}
@@ -0,0 +1,127 @@
+package memory
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+/*
+Intel HEX
+---------
+Every line is a record: ':', a byte count, a 16 bit address, a record type,
+the data and a checksum, all in hex. The checksum makes the sum of every byte
+in the record 0.
+
+Extended segment and linear address records move the records after them
+somewhere else, anywhere past $FFFF is an error. Start address records are
+accepted and ignored.
+*/
+
+const (
+	ihexData = iota
+	ihexEOF
+	ihexExtendedSegment
+	ihexStartSegment
+	ihexExtendedLinear
+	ihexStartLinear
+)
+
+// Bytes per data record when saving
+const ihexRecordSize = 16
+
+func (m *Memory) LoadIntelHex(r io.Reader) (err error) {
+	rr := newRecordReader(r)
+	var chunks []chunk
+	base := 0
+
+	for {
+		line, ok := rr.next()
+		if !ok {
+			if err = rr.err(); err != nil {
+				return err
+			}
+			return rr.errorf("Missing end of file record")
+		}
+
+		if !strings.HasPrefix(line, ":") {
+			return rr.errorf("Record doesn't start with ':'")
+		}
+
+		record, err := rr.decode(line[1:])
+		if err != nil {
+			return err
+		}
+		if len(record) < 5 {
+			return rr.errorf("Record is too short")
+		}
+		if len(record) != int(record[0])+5 {
+			return rr.errorf("Record is %v bytes long, expected %v", len(record), int(record[0])+5)
+		}
+
+		var sum byte
+		for _, b := range record {
+			sum += b
+		}
+		if sum != 0 {
+			last := len(record) - 1
+			return rr.errorf("Checksum is %#02x, expected %#02x", record[last], record[last]-sum)
+		}
+
+		address := int(record[1])<<8 | int(record[2])
+		data := record[4 : len(record)-1]
+
+		switch record[3] {
+		case ihexData:
+			if err = checkFits(base+address, len(data)); err != nil {
+				return rr.errorf("%v", err)
+			}
+			chunks = append(chunks, chunk{uint16(base + address), data})
+		case ihexEOF:
+			m.loadChunks(chunks)
+			return nil
+		case ihexExtendedSegment, ihexExtendedLinear:
+			if len(data) != 2 {
+				return rr.errorf("Address record has %v bytes of data, expected 2", len(data))
+			}
+			base = int(data[0])<<8 | int(data[1])
+			if record[3] == ihexExtendedSegment {
+				base <<= 4
+			} else {
+				base <<= 16
+			}
+		case ihexStartSegment, ihexStartLinear:
+			// Nothing to do with an entry point
+		default:
+			return rr.errorf("Unknown record type %#02x", record[3])
+		}
+	}
+}
+
+func (m *Memory) SaveIntelHex(w io.Writer, start, end uint16) (err error) {
+	// Writes start to end inclusive as data records followed by an end of file record
+	chunks, err := m.recordChunks(start, end, ihexRecordSize)
+	if err != nil {
+		return err
+	}
+
+	var records []string
+	for _, c := range chunks {
+		records = append(records, ihexRecord(ihexData, c.address, c.data))
+	}
+	records = append(records, ihexRecord(ihexEOF, 0, nil))
+
+	return writeRecords(w, records)
+}
+
+func ihexRecord(recordType byte, address uint16, data []byte) string {
+	record := append([]byte{byte(len(data)), byte(address >> 8), byte(address), recordType}, data...)
+
+	var sum byte
+	for _, b := range record {
+		sum += b
+	}
+	record = append(record, -sum)
+
+	return ":" + strings.ToUpper(fmt.Sprintf("%x", record))
+}
@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"bytes"
+	"github.com/edison-moreland/go6502/testingHelp"
+	"strings"
+	"testing"
+)
+
+func TestMemory_LoadIntelHex(t *testing.T) {
+	memory := new(Memory)
+	err := memory.LoadIntelHex(strings.NewReader(
+		":10010000214601360121470136007EFE09D2190140\n" +
+			":00000001FF\n",
+	))
+	testingHelp.NotNil(t, err)
+	testingHelp.Equals(t, []byte{0x21, 0x46, 0x01, 0x36, 0x01, 0x21, 0x47, 0x01}, memory.Mem[0x0100:0x0108])
+	testingHelp.Equals(t, byte(0x01), memory.Mem[0x010F])
+}
+
+func TestMemory_SaveIntelHex(t *testing.T) {
+	memory := new(Memory)
+	for i := range memory.Mem[0xC000:0xC020] {
+		memory.Mem[0xC000+i] = byte(i * 3)
+	}
+
+	var saved bytes.Buffer
+	testingHelp.NotNil(t, memory.SaveIntelHex(&saved, 0xC000, 0xC01F))
+
+	loaded := new(Memory)
+	testingHelp.NotNil(t, loaded.LoadIntelHex(&saved))
+	testingHelp.Equals(t, memory.Mem, loaded.Mem)
+}
+
+func TestMemory_LoadIntelHexErrors(t *testing.T) {
+	tests := map[string]string{
+		"bad checksum":    ":0300300002337A1F\n:00000001FF\n",
+		"past 64K":        ":020000040001F9\n:0300300002337A1E\n:00000001FF\n",
+		"over the end":    ":03FFFE0002337A51\n:00000001FF\n",
+		"no end of file":  ":0300300002337A1E\n",
+		"wrong length":    ":0400300002337A1E\n:00000001FF\n",
+		"not a record":    "0300300002337A1E\n:00000001FF\n",
+		"bad hex":         ":03003000023G7A1E\n:00000001FF\n",
+		"unknown type":    ":00000006FA\n:00000001FF\n",
+		"too short":       ":0000\n:00000001FF\n",
+		"partial address": ":0100000400FB\n:00000001FF\n",
+	}
+
+	for name, file := range tests {
+		memory := new(Memory)
+		err := memory.LoadIntelHex(strings.NewReader(file))
+		testingHelp.Assert(t, err != nil, "%v: expected an error", name)
+
+		// Nothing gets written from a bad file
+		testingHelp.Equals(t, Memory{}.Mem, memory.Mem)
+	}
+}
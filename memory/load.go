@@ -0,0 +1,178 @@
+package memory
+
+import (
+	"bufio"
+	"encoding/hex"
+	"github.com/pkg/errors"
+	"io"
+	"strings"
+)
+
+/*
+Loading and saving
+------------------
+Programs can be loaded from and saved to these formats:
+Raw: Just the bytes, loaded at an offset
+PRG: C64 program, a little endian load address followed by the bytes
+Intel HEX: Text records, see ihex.go
+Motorola S-record: Text records, see srec.go
+
+Loading writes to Mem directly, so ROM images can be loaded into read-only
+regions. Anything that would go past $FFFF is an error. Every format is read
+and checked completely before anything is written, so a file that's too big or
+can't be read leaves memory untouched.
+*/
+
+// chunk is a run of bytes from a text format, waiting to be written
+type chunk struct {
+	address uint16
+	data    []byte
+}
+
+func checkFits(address int, length int) (err error) {
+	// Errors if address to address+length runs past the end of memory
+	if address < 0 || address+length > len(Memory{}.Mem) {
+		return errors.Errorf("%v bytes at %#04x don't fit in 64K", length, address)
+	}
+
+	return nil
+}
+
+func (m *Memory) loadChunks(chunks []chunk) {
+	for _, c := range chunks {
+		copy(m.Mem[c.address:], c.data)
+	}
+}
+
+func (m *Memory) loadWindow(r io.Reader, start, end uint16) (n int, err error) {
+	// Reads everything from r into start to end inclusive, errors if there's more
+	// One byte more than the window fits, if that gets filled the data is too big
+	buffer := make([]byte, int(end)-int(start)+2)
+	n, err = io.ReadFull(r, buffer)
+	switch {
+	case err == nil:
+		return 0, errors.Errorf("Data doesn't fit in %#04x-%#04x", start, end)
+	case err != io.EOF && err != io.ErrUnexpectedEOF:
+		return 0, errors.Wrap(err, "Error reading data")
+	}
+
+	// Only touch memory once everything was read
+	copy(m.Mem[start:], buffer[:n])
+	return n, nil
+}
+
+func (m *Memory) LoadRaw(r io.Reader, offset uint16) (n int, err error) {
+	// Loads all of r at offset, returns how many bytes were loaded
+	return m.loadWindow(r, offset, 0xFFFF)
+}
+
+func (m *Memory) SaveRaw(w io.Writer, start, end uint16) (err error) {
+	// Writes start to end inclusive
+	if end < start {
+		return errors.Errorf("Range %#04x-%#04x ends before it starts", start, end)
+	}
+
+	if _, err = w.Write(m.Mem[start : int(end)+1]); err != nil {
+		return errors.Wrap(err, "Error writing data")
+	}
+
+	return nil
+}
+
+func (m *Memory) LoadPRG(r io.Reader) (start uint16, end uint16, err error) {
+	// Loads a PRG at the address in its header, returns where it ended up
+	var header [2]byte
+	if _, err = io.ReadFull(r, header[:]); err != nil {
+		return 0, 0, errors.Wrap(err, "Error reading PRG load address")
+	}
+
+	start, _ = BytesToWord(header)
+	n, err := m.loadWindow(r, start, 0xFFFF)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "Error loading PRG")
+	}
+
+	if n == 0 {
+		return start, start, nil
+	}
+	return start, start + uint16(n-1), nil
+}
+
+func (m *Memory) SavePRG(w io.Writer, start, end uint16) (err error) {
+	// Writes start to end inclusive with start as the load address
+	header := WordToBytes(start)
+	if _, err = w.Write(header[:]); err != nil {
+		return errors.Wrap(err, "Error writing PRG load address")
+	}
+
+	return m.SaveRaw(w, start, end)
+}
+
+// Reads text records one line at a time, for Intel HEX and S-records
+type recordReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newRecordReader(r io.Reader) *recordReader {
+	return &recordReader{scanner: bufio.NewScanner(r)}
+}
+
+func (rr *recordReader) next() (record string, ok bool) {
+	// Next non-blank line, trimmed
+	for rr.scanner.Scan() {
+		rr.line++
+		if record = strings.TrimSpace(rr.scanner.Text()); record != "" {
+			return record, true
+		}
+	}
+
+	return "", false
+}
+
+func (rr *recordReader) err() error {
+	if err := rr.scanner.Err(); err != nil {
+		return errors.Wrapf(err, "Error reading line %v", rr.line+1)
+	}
+
+	return nil
+}
+
+func (rr *recordReader) errorf(format string, args ...interface{}) error {
+	return errors.Wrapf(errors.Errorf(format, args...), "Line %v", rr.line)
+}
+
+func (rr *recordReader) decode(digits string) (record []byte, err error) {
+	if record, err = hex.DecodeString(digits); err != nil {
+		return nil, errors.Wrapf(err, "Line %v", rr.line)
+	}
+
+	return record, nil
+}
+
+func writeRecords(w io.Writer, records []string) (err error) {
+	for _, record := range records {
+		if _, err = io.WriteString(w, record+"\n"); err != nil {
+			return errors.Wrap(err, "Error writing record")
+		}
+	}
+
+	return nil
+}
+
+func (m *Memory) recordChunks(start, end uint16, size int) (chunks []chunk, err error) {
+	// Splits start to end inclusive into pieces of at most size bytes
+	if end < start {
+		return nil, errors.Errorf("Range %#04x-%#04x ends before it starts", start, end)
+	}
+
+	for address := int(start); address <= int(end); address += size {
+		length := size
+		if address+length > int(end)+1 {
+			length = int(end) + 1 - address
+		}
+		chunks = append(chunks, chunk{uint16(address), m.Mem[address : address+length]})
+	}
+
+	return chunks, nil
+}
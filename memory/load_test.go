@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"bytes"
+	"github.com/edison-moreland/go6502/testingHelp"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMemory_LoadRaw(t *testing.T) {
+	memory := new(Memory)
+	n, err := memory.LoadRaw(bytes.NewReader([]byte{0xA9, 0x01, 0x60}), 0xC000)
+	testingHelp.NotNil(t, err)
+	testingHelp.Equals(t, 3, n)
+	testingHelp.Equals(t, []byte{0xA9, 0x01, 0x60}, memory.Mem[0xC000:0xC003])
+
+	var saved bytes.Buffer
+	testingHelp.NotNil(t, memory.SaveRaw(&saved, 0xC000, 0xC002))
+	testingHelp.Equals(t, []byte{0xA9, 0x01, 0x60}, saved.Bytes())
+
+	// Filling memory right to the end is fine, one more byte isn't
+	_, err = memory.LoadRaw(bytes.NewReader(make([]byte, 0x10)), 0xFFF0)
+	testingHelp.NotNil(t, err)
+	overflow := bytes.Repeat([]byte{0xEE}, 0x11)
+	_, err = memory.LoadRaw(bytes.NewReader(overflow), 0xFFF0)
+	testingHelp.Assert(t, err != nil, "expected loading past $FFFF to fail")
+
+	// Nothing gets written from data that doesn't fit
+	testingHelp.Equals(t, make([]byte, 0x10), memory.Mem[0xFFF0:])
+}
+
+func TestMemory_LoadPRG(t *testing.T) {
+	memory := new(Memory)
+	start, end, err := memory.LoadPRG(bytes.NewReader([]byte{0x01, 0x08, 0x0B, 0x08, 0x0A, 0x00}))
+	testingHelp.NotNil(t, err)
+	testingHelp.Equals(t, uint16(0x0801), start)
+	testingHelp.Equals(t, uint16(0x0804), end)
+	testingHelp.Equals(t, []byte{0x0B, 0x08, 0x0A, 0x00}, memory.Mem[0x0801:0x0805])
+
+	var saved bytes.Buffer
+	testingHelp.NotNil(t, memory.SavePRG(&saved, 0x0801, 0x0804))
+	testingHelp.Equals(t, []byte{0x01, 0x08, 0x0B, 0x08, 0x0A, 0x00}, saved.Bytes())
+
+	_, _, err = memory.LoadPRG(bytes.NewReader([]byte{0x01}))
+	testingHelp.Assert(t, err != nil, "expected a truncated header to fail")
+
+	_, _, err = memory.LoadPRG(bytes.NewReader([]byte{0xFF, 0xFF, 0xEE, 0xEE}))
+	testingHelp.Assert(t, err != nil, "expected loading past $FFFF to fail")
+	testingHelp.Equals(t, byte(0x00), memory.Mem[0xFFFF])
+}
+
+func TestMemory_LoadMem(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go6502")
+	testingHelp.NotNil(t, err)
+	defer os.RemoveAll(dir)
+
+	rom := bytes.Repeat([]byte{0xEA}, 0x2000)
+	rom[0x1FFF] = 0xE3
+	path := filepath.Join(dir, "rom.bin")
+	testingHelp.NotNil(t, ioutil.WriteFile(path, rom, 0644))
+
+	// The end address is inclusive, so the last byte makes it in
+	memory := new(Memory)
+	testingHelp.NotNil(t, memory.LoadMem(path, 0xE000, 0xFFFF))
+	testingHelp.Equals(t, byte(0xE3), memory.Mem[0xFFFF])
+
+	err = memory.LoadMem(path, 0xA000, 0xAFFF)
+	testingHelp.Assert(t, err != nil, "expected a file bigger than the range to fail")
+	testingHelp.Equals(t, make([]byte, 0x1000), memory.Mem[0xA000:0xB000])
+
+	err = memory.LoadMem(filepath.Join(dir, "missing.bin"), 0xA000, 0xBFFF)
+	testingHelp.Assert(t, err != nil, "expected a missing file to fail")
+}
@@ -28,22 +28,27 @@ type Memory struct {
 	// +1 so the full range of 16bit numbers can be used as an address
 	// Just 0xFFFF would mean Memory.mem[0xFFFF] causes an out of bounds
 	Mem [0xFFFF + 1]byte
+
+	// WritePolicy decides what happens to writes to read-only addresses
+	WritePolicy WritePolicy
+	readOnly    *readOnlyMap
 }
 
 func (m *Memory) LoadMem(path string, startAddress uint16, endAddress uint16) (err error) {
-	// Load file at path into 6502 memory, WARNING: OVERWRITES MEMORY
-	file, err := os.Open(path)
-	defer file.Close() // make sure file get's closed
+	// Load file at path into startAddress to endAddress inclusive, WARNING: OVERWRITES MEMORY
+	if endAddress < startAddress {
+		return errors.Errorf("Range %#04x-%#04x ends before it starts", startAddress, endAddress)
+	}
 
+	file, err := os.Open(path)
 	if err != nil {
 		err = errors.WithStack(err)
 		return errors.Wrapf(err, "Error opening file: %v", path)
 	}
+	defer file.Close() // make sure file get's closed
 
 	// Read file contents to mem
-	_, err = file.Read(m.Mem[startAddress:endAddress])
-	if err != nil {
-		err = errors.WithStack(err)
+	if _, err = m.loadWindow(file, startAddress, endAddress); err != nil {
 		return errors.Wrapf(err, "Error reading file: %v", path)
 	}
 
@@ -71,11 +76,20 @@ func (m *Memory) WriteWord(loc uint16, word uint16) (err error) {
 	// Separate word into two bytes
 	rawWord := WordToBytes(word)
 
-	// Write both bytes to mem
-	m.Mem[loc] = rawWord[0]
-	m.Mem[loc+1] = rawWord[1]
+	// A rejected word isn't written at all, the other policies still write the unprotected half
+	if m.WritePolicy == RejectWrites && m.readOnly != nil {
+		for i, address := range [2]uint16{loc, loc + 1} {
+			if m.readOnly.contains(address) {
+				return m.rejectWrite(address, rawWord[i])
+			}
+		}
+	}
 
-	return nil
+	// Write both bytes to mem
+	if err = m.WriteByte(loc, rawWord[0]); err != nil {
+		return err
+	}
+	return m.WriteByte(loc+1, rawWord[1])
 }
 
 func (m *Memory) ReadByte(loc uint16) (memByte byte, err error) {
@@ -86,6 +100,14 @@ func (m *Memory) ReadByte(loc uint16) (memByte byte, err error) {
 
 func (m *Memory) WriteByte(loc uint16, memByte byte) (err error) {
 	//defer panicRecovery(&err)
+	if m.readOnly != nil && m.readOnly.contains(loc) {
+		return m.rejectWrite(loc, memByte)
+	}
+
 	m.Mem[loc] = memByte
 	return nil
 }
+
+func (m *Memory) PeekByte(loc uint16) byte {
+	return m.Mem[loc]
+}
@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"fmt"
+	"log"
+)
+
+/*
+Read-only regions
+-----------------
+Any address in Memory can be marked read-only. What happens when something
+writes to one is up to WritePolicy: the write is dropped quietly, dropped and
+logged, or refused with a ReadOnlyError. The write never reaches Mem either way.
+A refused WriteWord writes neither byte, even if only one of them is protected.
+
+Only WriteByte and WriteWord check, loading into Mem directly still works, so
+ROM images can be loaded after their region is protected.
+*/
+
+// WritePolicy decides what happens to writes to read-only addresses
+type WritePolicy byte
+
+const (
+	// IgnoreWrites drops the write, like writing to a real ROM
+	IgnoreWrites WritePolicy = iota
+	// LogWrites drops the write and logs it
+	LogWrites
+	// RejectWrites drops the write and returns a ReadOnlyError
+	RejectWrites
+)
+
+// ReadOnlyError is returned for writes to read-only addresses under RejectWrites
+type ReadOnlyError struct {
+	Address uint16
+	Value   byte
+	// PC of the instruction that did the write, filled in by the CPU
+	PC uint16
+}
+
+func (e *ReadOnlyError) Error() string {
+	return fmt.Sprintf("Write of %#02x to read-only address %#04x at PC %#04x", e.Value, e.Address, e.PC)
+}
+
+// One bit per address, set if it's read-only
+type readOnlyMap [(0xFFFF + 1) / 8]byte
+
+func (r *readOnlyMap) set(start, end uint16, readOnly bool) {
+	for address := int(start); address <= int(end); address++ {
+		if readOnly {
+			r[address>>3] |= 1 << uint(address&7)
+		} else {
+			r[address>>3] &^= 1 << uint(address&7)
+		}
+	}
+}
+
+func (r *readOnlyMap) contains(address uint16) bool {
+	return r[address>>3]&(1<<(address&7)) != 0
+}
+
+func (m *Memory) SetReadOnly(start, end uint16) {
+	// Marks start to end inclusive as read-only
+	if m.readOnly == nil {
+		m.readOnly = new(readOnlyMap)
+	}
+
+	m.readOnly.set(start, end, true)
+}
+
+func (m *Memory) ClearReadOnly(start, end uint16) {
+	// Makes start to end inclusive writable again
+	if m.readOnly != nil {
+		m.readOnly.set(start, end, false)
+	}
+}
+
+func (m *Memory) IsReadOnly(address uint16) bool {
+	return m.readOnly != nil && m.readOnly.contains(address)
+}
+
+func (m *Memory) rejectWrite(address uint16, value byte) (err error) {
+	// Applies WritePolicy to a write to a read-only address
+	switch m.WritePolicy {
+	case LogWrites:
+		log.Printf("Ignored write of %#02x to read-only address %#04x", value, address)
+	case RejectWrites:
+		return &ReadOnlyError{Address: address, Value: value}
+	}
+
+	return nil
+}
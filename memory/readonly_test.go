@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"github.com/edison-moreland/go6502/testingHelp"
+	"testing"
+)
+
+func TestMemory_ReadOnlyIgnore(t *testing.T) {
+	memory := new(Memory)
+	memory.Mem[0xE000] = 0x4C
+	memory.SetReadOnly(0xE000, 0xFFFF)
+
+	testingHelp.NotNil(t, memory.WriteByte(0xE000, 0x00))
+	testingHelp.NotNil(t, memory.WriteByte(0xDFFF, 0x11))
+	testingHelp.Equals(t, byte(0x4C), memory.Mem[0xE000])
+	testingHelp.Equals(t, byte(0x11), memory.Mem[0xDFFF])
+
+	// Words straddling the edge only lose the protected half
+	testingHelp.NotNil(t, memory.WriteWord(0xDFFF, 0x2233))
+	testingHelp.Equals(t, byte(0x33), memory.Mem[0xDFFF])
+	testingHelp.Equals(t, byte(0x4C), memory.Mem[0xE000])
+}
+
+func TestMemory_ReadOnlyReject(t *testing.T) {
+	memory := new(Memory)
+	memory.WritePolicy = RejectWrites
+	memory.SetReadOnly(0xA000, 0xBFFF)
+
+	err := memory.WriteByte(0xB123, 0x55)
+	readOnly, ok := err.(*ReadOnlyError)
+	testingHelp.Assert(t, ok, "expected a ReadOnlyError, got %v", err)
+	testingHelp.Equals(t, uint16(0xB123), readOnly.Address)
+	testingHelp.Equals(t, byte(0x55), readOnly.Value)
+	testingHelp.Equals(t, byte(0x00), memory.Mem[0xB123])
+
+	// Cleared regions are writable again
+	memory.ClearReadOnly(0xB000, 0xBFFF)
+	testingHelp.NotNil(t, memory.WriteByte(0xB123, 0x55))
+	testingHelp.Equals(t, byte(0x55), memory.Mem[0xB123])
+	testingHelp.Assert(t, memory.IsReadOnly(0xAFFF), "expected $AFFF to still be read-only")
+}
+
+func TestMemory_ReadOnlyRejectWord(t *testing.T) {
+	memory := new(Memory)
+	memory.WritePolicy = RejectWrites
+	memory.SetReadOnly(0xE000, 0xFFFF)
+
+	// Only the high byte is protected, the low byte mustn't be written either
+	err := memory.WriteWord(0xDFFF, 0x2233)
+	readOnly, ok := err.(*ReadOnlyError)
+	testingHelp.Assert(t, ok, "expected a ReadOnlyError, got %v", err)
+	testingHelp.Equals(t, uint16(0xE000), readOnly.Address)
+	testingHelp.Equals(t, byte(0x22), readOnly.Value)
+	testingHelp.Equals(t, byte(0x00), memory.Mem[0xDFFF])
+}
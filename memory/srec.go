@@ -0,0 +1,111 @@
+package memory
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+/*
+Motorola S-record
+-----------------
+Every line is a record: 'S', a record type, a byte count, an address, the data
+and a checksum, all in hex. The count covers the address, data and checksum.
+The checksum is the ones' complement of the sum of the count, address and data.
+
+S1, S2 and S3 are data with 16, 24 and 32 bit addresses, anything past $FFFF
+is an error. S7, S8 and S9 end the file. Headers (S0) and record counts (S5,
+S6) are checked but otherwise ignored.
+*/
+
+// Bytes per data record when saving
+const srecRecordSize = 16
+
+// Address length for each record type, 0 for types that don't exist
+var srecAddressSize = [10]int{2, 2, 3, 4, 0, 2, 3, 4, 3, 2}
+
+func (m *Memory) LoadSRecord(r io.Reader) (err error) {
+	rr := newRecordReader(r)
+	var chunks []chunk
+
+	for {
+		line, ok := rr.next()
+		if !ok {
+			if err = rr.err(); err != nil {
+				return err
+			}
+			return rr.errorf("Missing termination record")
+		}
+
+		if len(line) < 2 || line[0] != 'S' || line[1] < '0' || line[1] > '9' || srecAddressSize[line[1]-'0'] == 0 {
+			return rr.errorf("Record doesn't start with a known type")
+		}
+		recordType := line[1] - '0'
+		addressSize := srecAddressSize[recordType]
+
+		record, err := rr.decode(line[2:])
+		if err != nil {
+			return err
+		}
+		if len(record) < addressSize+2 {
+			return rr.errorf("Record is too short")
+		}
+		if len(record) != int(record[0])+1 {
+			return rr.errorf("Record is %v bytes long, expected %v", len(record), int(record[0])+1)
+		}
+
+		var sum byte
+		for _, b := range record {
+			sum += b
+		}
+		if sum != 0xFF {
+			last := len(record) - 1
+			return rr.errorf("Checksum is %#02x, expected %#02x", record[last], ^(sum - record[last]))
+		}
+
+		address := 0
+		for _, b := range record[1 : 1+addressSize] {
+			address = address<<8 | int(b)
+		}
+		data := record[1+addressSize : len(record)-1]
+
+		switch recordType {
+		case 1, 2, 3:
+			if err = checkFits(address, len(data)); err != nil {
+				return rr.errorf("%v", err)
+			}
+			chunks = append(chunks, chunk{uint16(address), data})
+		case 7, 8, 9:
+			m.loadChunks(chunks)
+			return nil
+		}
+	}
+}
+
+func (m *Memory) SaveSRecord(w io.Writer, start, end uint16) (err error) {
+	// Writes start to end inclusive as S1 records followed by an S9
+	chunks, err := m.recordChunks(start, end, srecRecordSize)
+	if err != nil {
+		return err
+	}
+
+	var records []string
+	for _, c := range chunks {
+		records = append(records, srecRecord(1, c.address, c.data))
+	}
+	records = append(records, srecRecord(9, 0, nil))
+
+	return writeRecords(w, records)
+}
+
+func srecRecord(recordType byte, address uint16, data []byte) string {
+	record := append([]byte{byte(len(data) + 3), byte(address >> 8), byte(address)}, data...)
+
+	var sum byte
+	for _, b := range record {
+		sum += b
+	}
+	record = append(record, ^sum)
+
+	return fmt.Sprintf("S%d", recordType) + strings.ToUpper(fmt.Sprintf("%x", record))
+}
@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"bytes"
+	"github.com/edison-moreland/go6502/testingHelp"
+	"strings"
+	"testing"
+)
+
+func TestMemory_LoadSRecord(t *testing.T) {
+	memory := new(Memory)
+	err := memory.LoadSRecord(strings.NewReader(
+		"S00F000068656C6C6F202020202000003C\n" +
+			"S11F00007C0802A6900100049421FFF07C6C1B787C8C23783C6000003863000026\n" +
+			"S5030001FB\n" +
+			"S9030000FC\n",
+	))
+	testingHelp.NotNil(t, err)
+	testingHelp.Equals(t, []byte{0x7C, 0x08, 0x02, 0xA6}, memory.Mem[0x0000:0x0004])
+	testingHelp.Equals(t, byte(0x00), memory.Mem[0x001B])
+}
+
+func TestMemory_SaveSRecord(t *testing.T) {
+	memory := new(Memory)
+	for i := range memory.Mem[0xFFE0:] {
+		memory.Mem[0xFFE0+i] = byte(i * 7)
+	}
+
+	var saved bytes.Buffer
+	testingHelp.NotNil(t, memory.SaveSRecord(&saved, 0xFFE0, 0xFFFF))
+
+	loaded := new(Memory)
+	testingHelp.NotNil(t, loaded.LoadSRecord(&saved))
+	testingHelp.Equals(t, memory.Mem, loaded.Mem)
+}
+
+func TestMemory_LoadSRecordErrors(t *testing.T) {
+	tests := map[string]string{
+		"bad checksum":   "S106003002337A1B\nS9030000FC\n",
+		"past 64K":       "S20701003002337A18\nS9030000FC\n",
+		"over the end":   "S106FFFE02337A4D\nS9030000FC\n",
+		"no termination": "S106003002337A1A\n",
+		"wrong length":   "S107003002337A1A\nS9030000FC\n",
+		"unknown type":   "S4030000FC\nS9030000FC\n",
+		"bad hex":        "S10600300233G6\nS9030000FC\n",
+		"too short":      "S101FE\nS9030000FC\n",
+	}
+
+	for name, file := range tests {
+		memory := new(Memory)
+		err := memory.LoadSRecord(strings.NewReader(file))
+		testingHelp.Assert(t, err != nil, "%v: expected an error", name)
+
+		// Nothing gets written from a bad file
+		testingHelp.Equals(t, Memory{}.Mem, memory.Mem)
+	}
+}
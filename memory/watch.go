@@ -0,0 +1,134 @@
+package memory
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+)
+
+/*
+Watchpoints
+-----------
+A watchpoint covers a range of addresses and any mix of reads, writes and
+execution. Whoever does the access calls Check, which runs the handler of every
+matching watchpoint and says whether one of them wants execution to stop. The
+CPU checks every access it makes, reads include opcode and operand fetches.
+
+Condition narrows a watchpoint down to certain values, it gets the value before
+and after the access. For reads and execution both are the value read.
+*/
+
+// Access is a kind of memory access, they can be combined to watch several at once
+type Access byte
+
+const (
+	AccessRead Access = 1 << iota
+	AccessWrite
+	AccessExecute
+)
+
+func (a Access) String() string {
+	switch a {
+	case AccessRead:
+		return "read"
+	case AccessWrite:
+		return "write"
+	case AccessExecute:
+		return "execute"
+	}
+
+	return fmt.Sprintf("Access(%#02x)", byte(a))
+}
+
+type Watchpoint struct {
+	// Start to End inclusive is watched
+	Start, End uint16
+	Access     Access
+
+	// Condition is optional, the watchpoint only triggers if it returns true
+	Condition func(old, new byte) bool
+	// Handler is optional, it's called every time the watchpoint triggers
+	Handler func(hit Hit)
+	// Stop asks whoever is accessing memory to stop once the access is finished
+	Stop bool
+}
+
+// Hit describes the access that triggered a watchpoint
+type Hit struct {
+	Watchpoint *Watchpoint
+	Access     Access
+	Address    uint16
+	// PC of the instruction doing the access
+	PC       uint16
+	Old, New byte
+}
+
+func (h Hit) String() string {
+	return fmt.Sprintf("%v of %#02x at %#04x (was %#02x) by PC %#04x", h.Access, h.New, h.Address, h.Old, h.PC)
+}
+
+type Watchpoints struct {
+	// Watchpoints touching each page
+	pages [0x100][]*Watchpoint
+	count int
+}
+
+func (w *Watchpoints) Add(wp *Watchpoint) (err error) {
+	if wp.End < wp.Start {
+		return errors.Errorf("Watchpoint %#04x-%#04x ends before it starts", wp.Start, wp.End)
+	}
+
+	for page := int(wp.Start >> 8); page <= int(wp.End>>8); page++ {
+		w.pages[page] = append(w.pages[page], wp)
+	}
+	w.count++
+
+	return nil
+}
+
+func (w *Watchpoints) Remove(wp *Watchpoint) (err error) {
+	found := false
+	for page := int(wp.Start >> 8); page <= int(wp.End>>8); page++ {
+		kept := w.pages[page][:0]
+		for _, existing := range w.pages[page] {
+			if existing == wp {
+				found = true
+				continue
+			}
+			kept = append(kept, existing)
+		}
+		w.pages[page] = kept
+	}
+
+	if !found {
+		return errors.Errorf("Watchpoint %#04x-%#04x isn't set", wp.Start, wp.End)
+	}
+
+	w.count--
+	return nil
+}
+
+func (w *Watchpoints) Len() int {
+	return w.count
+}
+
+func (w *Watchpoints) Check(access Access, address, pc uint16, old, new byte) (stop *Hit) {
+	// Triggers every watchpoint matching the access, returns the first that wants to stop
+	for _, wp := range w.pages[address>>8] {
+		if wp.Access&access == 0 || address < wp.Start || address > wp.End {
+			continue
+		}
+		if wp.Condition != nil && !wp.Condition(old, new) {
+			continue
+		}
+
+		hit := Hit{wp, access, address, pc, old, new}
+		if wp.Handler != nil {
+			wp.Handler(hit)
+		}
+		if wp.Stop && stop == nil {
+			stop = &hit
+		}
+	}
+
+	return stop
+}
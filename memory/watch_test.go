@@ -0,0 +1,40 @@
+package memory
+
+import (
+	"github.com/edison-moreland/go6502/testingHelp"
+	"testing"
+)
+
+func TestWatchpoints_Check(t *testing.T) {
+	var hits []Hit
+	wp := &Watchpoint{
+		Start: 0x10FF, End: 0x1100, Access: AccessWrite | AccessRead,
+		Condition: func(old, new byte) bool { return new >= 0x80 },
+		Handler:   func(hit Hit) { hits = append(hits, hit) },
+	}
+
+	var w Watchpoints
+	testingHelp.NotNil(t, w.Add(wp))
+
+	testingHelp.Assert(t, w.Check(AccessWrite, 0x1100, 0x2000, 0x00, 0x90) == nil, "expected no stop")
+	w.Check(AccessWrite, 0x1100, 0x2000, 0x00, 0x10)   // Fails the condition
+	w.Check(AccessExecute, 0x1100, 0x2000, 0x90, 0x90) // Not watched
+	w.Check(AccessRead, 0x1101, 0x2000, 0x90, 0x90)    // Out of range
+	w.Check(AccessRead, 0x10FF, 0x2003, 0x90, 0x90)
+
+	testingHelp.Equals(t, []Hit{
+		{wp, AccessWrite, 0x1100, 0x2000, 0x00, 0x90},
+		{wp, AccessRead, 0x10FF, 0x2003, 0x90, 0x90},
+	}, hits)
+
+	// Stopping watchpoints are reported back
+	wp.Stop = true
+	stop := w.Check(AccessWrite, 0x1100, 0x2006, 0x90, 0xA0)
+	testingHelp.Assert(t, stop != nil, "expected a stop")
+	testingHelp.Equals(t, uint16(0x2006), stop.PC)
+
+	testingHelp.NotNil(t, w.Remove(wp))
+	testingHelp.Equals(t, 0, w.Len())
+	testingHelp.Assert(t, w.Check(AccessWrite, 0x1100, 0x2006, 0x90, 0xA0) == nil, "expected removed watchpoint to be gone")
+	testingHelp.Assert(t, w.Remove(wp) != nil, "expected removing twice to fail")
+}
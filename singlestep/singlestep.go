@@ -0,0 +1,167 @@
+package singlestep
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/edison-moreland/go6502/cpu"
+	"github.com/pkg/errors"
+	"io"
+	"os"
+	"strings"
+)
+
+/*
+Single step tests
+-----------------
+Each test sets up the CPU and memory, runs exactly one instruction, then checks
+the registers, flags, memory and cycle count against the expected final state.
+Files are JSON arrays of tests, usually one file per opcode:
+
+{
+	"name": "a9 42 00",
+	"initial": {"pc": 512, "s": 253, "a": 0, "x": 0, "y": 0, "p": 36, "ram": [[512, 169], [513, 66]]},
+	"final":   {"pc": 514, "s": 253, "a": 66, "x": 0, "y": 0, "p": 36, "ram": [[512, 169], [513, 66]]},
+	"cycles":  [[512, 169, "read"], [513, 66, "read"]]
+}
+
+Go6502 isn't cycle accurate, only the number of cycles is checked not what
+happened on the bus during each one. P is compared without bits 4 and 5, they
+don't exist in the real status register.
+
+https://github.com/SingleStepTests/ProcessorTests
+*/
+
+// State of the CPU and the memory a test cares about
+type State struct {
+	PC  uint16      `json:"pc"`
+	S   byte        `json:"s"`
+	A   byte        `json:"a"`
+	X   byte        `json:"x"`
+	Y   byte        `json:"y"`
+	P   byte        `json:"p"`
+	RAM [][2]uint16 `json:"ram"`
+}
+
+type Test struct {
+	Name    string `json:"name"`
+	Initial State  `json:"initial"`
+	Final   State  `json:"final"`
+	// One entry per cycle, only the length is used
+	Cycles []json.RawMessage `json:"cycles"`
+}
+
+// Mismatch is a single difference between the expected and actual final state
+type Mismatch struct {
+	// Field is a register, a flag, "cycles", "error" or a memory address like "$01FD"
+	Field         string
+	Expected, Got int
+	// Err is set if the instruction failed to execute
+	Err error
+}
+
+func (m Mismatch) String() string {
+	if m.Err != nil {
+		return fmt.Sprintf("error: %v", m.Err)
+	}
+
+	return fmt.Sprintf("%v: expected %#02x, got %#02x", m.Field, m.Expected, m.Got)
+}
+
+// Failure is a test that didn't end up in its final state
+type Failure struct {
+	Name       string
+	Mismatches []Mismatch
+}
+
+func (f Failure) String() string {
+	mismatches := make([]string, len(f.Mismatches))
+	for i, mismatch := range f.Mismatches {
+		mismatches[i] = mismatch.String()
+	}
+
+	return fmt.Sprintf("%v: %v", f.Name, strings.Join(mismatches, ", "))
+}
+
+// Bits 4 and 5 of P aren't real flags
+const statusMask = 0xCF
+
+// Flags in P, highest bit first
+var flagNames = [8]string{"N", "V", "", "", "D", "I", "Z", "C"}
+
+func Load(r io.Reader) (tests []Test, err error) {
+	if err = json.NewDecoder(r).Decode(&tests); err != nil {
+		return nil, errors.Wrap(err, "Error decoding single step tests")
+	}
+
+	return tests, nil
+}
+
+func LoadFile(path string) (tests []Test, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error opening file: %v", path)
+	}
+	defer file.Close()
+
+	tests, err = Load(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error loading file: %v", path)
+	}
+
+	return tests, nil
+}
+
+func (test Test) Run(variant cpu.Variant) (mismatches []Mismatch) {
+	// Runs the test on a fresh CPU, returning every difference from the final state
+	g6 := cpu.New(cpu.WithVariant(variant))
+	g6.PC = test.Initial.PC
+	g6.SP = test.Initial.S
+	g6.A, g6.X, g6.Y = test.Initial.A, test.Initial.X, test.Initial.Y
+	g6.Stat.FromByte(test.Initial.P)
+	for _, ram := range test.Initial.RAM {
+		g6.Mem.Mem[ram[0]] = byte(ram[1])
+	}
+
+	result, err := g6.Step()
+	if err != nil {
+		return []Mismatch{{Field: "error", Err: err}}
+	}
+
+	check := func(field string, expected, got int) {
+		if expected != got {
+			mismatches = append(mismatches, Mismatch{Field: field, Expected: expected, Got: got})
+		}
+	}
+
+	check("PC", int(test.Final.PC), int(g6.PC))
+	check("S", int(test.Final.S), int(g6.SP))
+	check("A", int(test.Final.A), int(g6.A))
+	check("X", int(test.Final.X), int(g6.X))
+	check("Y", int(test.Final.Y), int(g6.Y))
+
+	// Each flag separately so it's obvious which one is wrong
+	expectedP, gotP := test.Final.P&statusMask, g6.Stat.AsByte(false)&statusMask
+	for bit, name := range flagNames {
+		mask := byte(0x80) >> uint(bit)
+		if name != "" {
+			check(name, int(expectedP&mask)>>(7-uint(bit)), int(gotP&mask)>>(7-uint(bit)))
+		}
+	}
+
+	for _, ram := range test.Final.RAM {
+		check(fmt.Sprintf("$%04X", ram[0]), int(ram[1]), int(g6.Mem.Mem[ram[0]]))
+	}
+
+	check("cycles", len(test.Cycles), int(result.Cycles))
+	return mismatches
+}
+
+func RunAll(tests []Test, variant cpu.Variant) (failures []Failure) {
+	for _, test := range tests {
+		if mismatches := test.Run(variant); len(mismatches) > 0 {
+			failures = append(failures, Failure{test.Name, mismatches})
+		}
+	}
+
+	return failures
+}
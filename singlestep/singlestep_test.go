@@ -0,0 +1,70 @@
+package singlestep
+
+import (
+	"github.com/edison-moreland/go6502/cpu"
+	"github.com/edison-moreland/go6502/testingHelp"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRegressions(t *testing.T) {
+	tests, err := LoadFile(filepath.Join("testdata", "regressions.json"))
+	testingHelp.NotNil(t, err)
+
+	for _, failure := range RunAll(tests, cpu.NMOS6502) {
+		t.Error(failure)
+	}
+}
+
+func TestRun_ReportsMismatches(t *testing.T) {
+	tests, err := Load(strings.NewReader(`[{
+		"name": "a9 42",
+		"initial": {"pc": 512, "s": 253, "a": 0, "x": 0, "y": 0, "p": 32, "ram": [[512, 169], [513, 66]]},
+		"final": {"pc": 514, "s": 253, "a": 67, "x": 0, "y": 0, "p": 160, "ram": [[513, 0]]},
+		"cycles": [0, 0, 0]
+	}]`))
+	testingHelp.NotNil(t, err)
+
+	failures := RunAll(tests, cpu.NMOS6502)
+	testingHelp.Equals(t, 1, len(failures))
+	testingHelp.Equals(t, []Mismatch{
+		{Field: "A", Expected: 0x43, Got: 0x42},
+		{Field: "N", Expected: 1, Got: 0},
+		{Field: "$0201", Expected: 0x00, Got: 0x42},
+		{Field: "cycles", Expected: 3, Got: 2},
+	}, failures[0].Mismatches)
+}
+
+func TestRun_Error(t *testing.T) {
+	// JAM halts the CPU
+	test := Test{Name: "02", Initial: State{PC: 0x0200, RAM: [][2]uint16{{0x0200, 0x02}}}}
+
+	mismatches := test.Run(cpu.NMOS6502)
+	testingHelp.Equals(t, 1, len(mismatches))
+	testingHelp.Equals(t, "error", mismatches[0].Field)
+}
+
+// The full test suite is too big for the repo, point GO6502_SINGLESTEP at a checkout to run it
+// https://github.com/SingleStepTests/ProcessorTests
+func TestProcessorTests(t *testing.T) {
+	root := os.Getenv("GO6502_SINGLESTEP")
+	if root == "" {
+		t.Skip("GO6502_SINGLESTEP not set")
+	}
+
+	suites := map[string]cpu.Variant{"6502": cpu.NMOS6502, "wdc65c02": cpu.WDC65C02}
+	for suite, variant := range suites {
+		files, _ := filepath.Glob(filepath.Join(root, suite, "v1", "*.json"))
+		for _, file := range files {
+			tests, err := LoadFile(file)
+			testingHelp.NotNil(t, err)
+
+			failures := RunAll(tests, variant)
+			if len(failures) > 0 {
+				t.Errorf("%v/%v: %v of %v tests failed, first: %v", suite, filepath.Base(file), len(failures), len(tests), failures[0])
+			}
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/edison-moreland/go6502/cpu"
+	"log"
+	"os"
+)
+
+// Compares a trace from cpu.SetTracer against a reference log, like nestest.log
+var ignoreDisassembly = flag.Bool("ignore-disassembly", false, "don't compare disassembly and memory annotations")
+var ignoreCycles = flag.Bool("ignore-cycles", false, "don't compare cycle counts")
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %v [flags] ours.log reference.log\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ours, err := os.Open(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer ours.Close()
+
+	reference, err := os.Open(flag.Arg(1))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer reference.Close()
+
+	divergence, err := cpu.DiffTrace(ours, reference, cpu.DiffOptions{
+		IgnoreDisassembly: *ignoreDisassembly,
+		IgnoreCycles:      *ignoreCycles,
+	})
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+
+	if divergence != nil {
+		fmt.Println(divergence)
+		os.Exit(1)
+	}
+
+	fmt.Println("Traces match")
+}